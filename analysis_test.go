@@ -1,6 +1,7 @@
 package gnubgparser
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -119,3 +120,131 @@ func TestCubeAnalysisValuesParsing(t *testing.T) {
 	t.Logf("  Player2BackgammonRate: %f", ca.Player2BackgammonRate)
 	t.Logf("  CubelessEquity: %f", ca.CubelessEquity)
 }
+
+func TestSelectPlayedMove(t *testing.T) {
+	options := []MoveOption{
+		{Move: [8]int{11, 15, 0, 1, -1}, Equity: 0.10},
+		{Move: [8]int{0, 4, -1}, Equity: 0.02},
+		{Move: [8]int{11, 14, 14, 15, -1}, Equity: -0.05},
+	}
+
+	tests := []struct {
+		name         string
+		move         [8]int
+		wantSelected int
+		wantLoss     float64
+	}{
+		{"best move", [8]int{11, 15, 0, 1, -1}, 0, 0},
+		{"submoves in another order", [8]int{0, 1, 11, 15, -1}, 0, 0},
+		{"chained hops merged", [8]int{0, 2, 2, 4, -1}, 1, 0.08},
+		{"exact match preferred over net movement", [8]int{14, 15, 11, 14, -1}, 2, 0.15},
+		{"not listed", [8]int{5, 1, 7, 4, -1}, SelectedMoveUnknown, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := MoveRecord{
+				Type:     MoveTypeNormal,
+				Move:     tt.move,
+				Analysis: &MoveAnalysis{Moves: options},
+			}
+			selectPlayedMove(&mr)
+			if mr.Analysis.SelectedMove != tt.wantSelected {
+				t.Errorf("SelectedMove = %d, want %d", mr.Analysis.SelectedMove, tt.wantSelected)
+			}
+			if diff := mr.Analysis.EquityLoss - tt.wantLoss; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("EquityLoss = %f, want %f", mr.Analysis.EquityLoss, tt.wantLoss)
+			}
+			if known := mr.Analysis.PlayedKnown(); known != (tt.wantSelected >= 0) {
+				t.Errorf("PlayedKnown() = %v", known)
+			}
+		})
+	}
+}
+
+func TestSelectedMoveInFile(t *testing.T) {
+	match, err := ParseSGFFile("test/charlot1-charlot2_7p_2025-11-08-2308.sgf")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	analysed := 0
+	for _, game := range match.Games {
+		for _, mr := range game.Moves {
+			if mr.Type != MoveTypeNormal || mr.Analysis == nil {
+				continue
+			}
+			analysed++
+			if mr.Analysis.SelectedMove < 0 {
+				t.Errorf("Game %d: played move %s not found in analysis", game.GameNumber, mr.MoveString)
+				continue
+			}
+			if mr.Analysis.EquityLoss < 0 {
+				t.Errorf("Game %d: negative equity loss %f", game.GameNumber, mr.Analysis.EquityLoss)
+			}
+		}
+	}
+	if analysed == 0 {
+		t.Fatal("No analysed checker plays found")
+	}
+}
+
+func TestPlayerPerspective(t *testing.T) {
+	// The same evaluation played by W (player 0) and B (player 1)
+	eval := `0.6 0.2 0.01 0.1 0.005`
+	sgf := `(;FF[4]GM[6]MI[length:7][game:0][ws:0][bs:0]
+;W[31fehe]A[0][fehe E ver 3 ` + eval + ` 0.3 2C 0 1 0.000000 1]DA[E ver 3 2C 1 0.000000 1 ` + eval + ` 0.3 0.5 ` + eval + ` 0.3 0.5]
+;B[31fehe]A[0][fehe E ver 3 ` + eval + ` 0.3 2C 0 1 0.000000 1]DA[E ver 3 2C 1 0.000000 1 ` + eval + ` 0.3 0.5 ` + eval + ` 0.3 0.5]
+;W[double]DA[E ver 3 2C 1 0.000000 1 ` + eval + ` 0.3 0.5 ` + eval + ` 0.3 0.5]
+;B[take]DA[E ver 3 2C 1 0.000000 1 ` + eval + ` 0.3 0.5 ` + eval + ` 0.3 0.5])
+`
+	match, err := ParseSGF(strings.NewReader(sgf))
+	if err != nil {
+		t.Fatalf("ParseSGF failed: %v", err)
+	}
+	moves := match.Games[0].Moves
+
+	near := func(a, b float32) bool { return a-b < 1e-6 && b-a < 1e-6 }
+
+	tests := []struct {
+		name   string
+		mr     MoveRecord
+		onRoll int // Player described by the on-roll fields
+	}{
+		{"W checker play", moves[0], 0},
+		{"B checker play", moves[1], 1},
+		{"W double", moves[2], 0},
+		{"B take (doubler's view)", moves[3], 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Absolute rates of the on-roll player and of the opponent
+			rates := [2][3]float32{}
+			var onRoll, opponent [3]float32
+			if tt.mr.Analysis != nil {
+				opt := tt.mr.Analysis.Moves[0]
+				rates[0] = [3]float32{opt.Player1WinRate, opt.Player1GammonRate, opt.Player1BackgammonRate}
+				rates[1] = [3]float32{opt.Player2WinRate, opt.Player2GammonRate, opt.Player2BackgammonRate}
+				onRoll = [3]float32{opt.OnRollWin, opt.OnRollGammon, opt.OnRollBackgammon}
+				opponent = [3]float32{opt.OpponentWin, opt.OpponentGammon, opt.OpponentBackgammon}
+			} else {
+				ca := tt.mr.CubeAnalysis
+				rates[0] = [3]float32{ca.Player1WinRate, ca.Player1GammonRate, ca.Player1BackgammonRate}
+				rates[1] = [3]float32{ca.Player2WinRate, ca.Player2GammonRate, ca.Player2BackgammonRate}
+				onRoll = [3]float32{ca.OnRollWin, ca.OnRollGammon, ca.OnRollBackgammon}
+				opponent = [3]float32{ca.OpponentWin, ca.OpponentGammon, ca.OpponentBackgammon}
+			}
+
+			if !near(onRoll[0], 0.6) || !near(opponent[0], 0.4) || !near(opponent[1], 0.1) {
+				t.Errorf("On-roll view = %v / %v, want win 0.6, opponent 0.4 with gammon 0.1", onRoll, opponent)
+			}
+			for i := 0; i < 3; i++ {
+				if !near(rates[tt.onRoll][i], onRoll[i]) || !near(rates[1-tt.onRoll][i], opponent[i]) {
+					t.Errorf("Absolute rates = %v, want player %d to hold %v", rates, tt.onRoll, onRoll)
+					break
+				}
+			}
+		})
+	}
+}
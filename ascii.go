@@ -0,0 +1,130 @@
+package gnubgparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ASCIIOptions controls how RenderASCII draws a position
+type ASCIIOptions struct {
+	// Player drawn at the bottom as X, whose side numbers the points
+	Bottom int
+	// Player names; empty names are shown as "Player 1" and "Player 2"
+	Names [2]string
+	// Checkers each player starts with, for borne-off counts (default 15)
+	Checkers int
+}
+
+// String draws the position with RenderASCII's default options
+func (p *Position) String() string {
+	return p.RenderASCII(ASCIIOptions{})
+}
+
+// RenderASCII draws the position as gnuBG's 24-point text diagram: checker
+// stacks (with a count when more than five), the bar, and to the right the
+// names, score, cube, borne-off checkers and dice, followed by the pip counts
+func (p *Position) RenderASCII(opts ASCIIOptions) string {
+	return strings.Join(asciiBoard(p, opts), "\n") + "\n"
+}
+
+// asciiBoard returns the lines of RenderASCII
+func asciiBoard(pos *Position, opts ASCIIOptions) []string {
+	const blank = "   "
+	x := opts.Bottom
+	if x != 1 {
+		x = 0
+	}
+	o := 1 - x
+	names := opts.Names
+	for i := range names {
+		if names[i] == "" {
+			names[i] = fmt.Sprintf("Player %d", i+1)
+		}
+	}
+	checkers := opts.Checkers
+	if checkers <= 0 {
+		checkers = 15
+	}
+
+	// cell returns the content of a point column at row r, counted from
+	// the edge of the board
+	cell := func(nx, no, r int) string {
+		n, sym := nx, "X"
+		if no > 0 {
+			n, sym = no, "O"
+		}
+		switch {
+		case n > 5 && r == 4:
+			return fmt.Sprintf("%2d ", n)
+		case n > r:
+			return " " + sym + " "
+		}
+		return blank
+	}
+	// half draws one half-row from X's points
+	half := func(points []int, r int) string {
+		var b strings.Builder
+		for _, i := range points {
+			b.WriteString(cell(pos.Board[x][i], pos.Board[o][23-i], r))
+		}
+		return b.String()
+	}
+	bar := func(player, r int) string {
+		if player == x {
+			return cell(pos.Board[x][24], 0, r)
+		}
+		return cell(0, pos.Board[o][24], r)
+	}
+
+	// Annotations right of the board, for each player's half
+	var notes [2][5]string
+	for p := 0; p < 2; p++ {
+		notes[p][0] = fmt.Sprintf("%d points", pos.Score[p])
+		if pos.Score[p] == 1 {
+			notes[p][0] = "1 point"
+		}
+		if pos.CubeOwner == p {
+			notes[p][1] = fmt.Sprintf("Cube: %d", pos.CubeValue)
+		}
+		if off := pos.BorneOff(p, checkers); off > 0 {
+			notes[p][2] = fmt.Sprintf("Off: %d", off)
+		}
+		if pos.OnRoll == p {
+			notes[p][3] = "On roll"
+			if pos.Dice[0] > 0 {
+				notes[p][3] = fmt.Sprintf("Rolled %d%d", pos.Dice[0], pos.Dice[1])
+			}
+		}
+	}
+	middle := "Money session"
+	if pos.MatchLength > 0 {
+		middle = fmt.Sprintf("%d point match", pos.MatchLength)
+	}
+	if pos.CubeOwner != 0 && pos.CubeOwner != 1 {
+		middle += fmt.Sprintf(" (Cube: %d)", pos.CubeValue)
+	}
+
+	note := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return "     " + s
+	}
+
+	top := []int{12, 13, 14, 15, 16, 17}
+	topHome := []int{18, 19, 20, 21, 22, 23}
+	bottom := []int{11, 10, 9, 8, 7, 6}
+	bottomHome := []int{5, 4, 3, 2, 1, 0}
+
+	lines := []string{" +13-14-15-16-17-18------19-20-21-22-23-24-+" + note("O: "+names[o])}
+	for r := 0; r < 5; r++ {
+		lines = append(lines, " |"+half(top, r)+"|"+bar(o, r)+"|"+half(topHome, r)+"|"+note(notes[o][r]))
+	}
+	lines = append(lines, "v|                  |BAR|                  |"+note(middle))
+	for r := 4; r >= 0; r-- {
+		lines = append(lines, " |"+half(bottom, r)+"|"+bar(x, r)+"|"+half(bottomHome, r)+"|"+note(notes[x][r]))
+	}
+	lines = append(lines, " +12-11-10--9--8--7-------6--5--4--3--2--1-+"+note("X: "+names[x]))
+	lines = append(lines, fmt.Sprintf("Pip count  O: %d  X: %d", pos.PipCount(o), pos.PipCount(x)))
+	return lines
+}
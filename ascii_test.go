@@ -0,0 +1,62 @@
+package gnubgparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderASCII(t *testing.T) {
+	pos := Position{Board: startingBoard("Standard"), CubeValue: 2, CubeOwner: 1, Score: [2]int{1, 3}, MatchLength: 5, Dice: [2]int{3, 1}}
+	pos.applyMove(0, []SubMove{{From: 7, To: 4}, {From: 5, To: 4}})
+
+	want := ` +13-14-15-16-17-18------19-20-21-22-23-24-+     O: Player 2
+ | X           O    |   | O              X |     3 points
+ | X           O    |   | O              X |     Cube: 2
+ | X           O    |   | O                |
+ | X                |   | O                |
+ | X                |   | O                |
+v|                  |BAR|                  |     5 point match
+ | O                |   |                  |
+ | O                |   | X                |     Rolled 31
+ | O                |   | X                |
+ | O           X    |   | X  X           O |
+ | O           X    |   | X  X           O |     1 point
+ +12-11-10--9--8--7-------6--5--4--3--2--1-+     X: Player 1
+Pip count  O: 167  X: 163
+`
+	if got := pos.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+
+	// From player 1's side the made 5-point is player 0's, on top at 20
+	flipped := pos.RenderASCII(ASCIIOptions{Bottom: 1, Names: [2]string{"Alice", "Bob"}})
+	lines := strings.Split(flipped, "\n")
+	if !strings.HasSuffix(lines[0], "O: Alice") || !strings.HasSuffix(lines[12], "X: Bob") {
+		t.Errorf("Flipped names:\n%s", flipped)
+	}
+	if lines[1] != " | X           O    |   | O  O           X |     1 point" {
+		t.Errorf("Flipped top row = %q", lines[1])
+	}
+	if lines[13] != "Pip count  O: 163  X: 167" {
+		t.Errorf("Flipped pip counts = %q", lines[13])
+	}
+}
+
+func TestRenderASCIIBarAndOff(t *testing.T) {
+	var pos Position
+	pos.Board[0][24] = 1 // Player 0 on the bar
+	pos.Board[0][0] = 7  // Tall stack on the 1-point
+	pos.Board[1][5] = 2
+	pos.CubeValue, pos.CubeOwner = 1, -1
+
+	lines := strings.Split(pos.RenderASCII(ASCIIOptions{}), "\n")
+	if got := lines[11]; !strings.HasPrefix(got, " |                  | X |") {
+		t.Errorf("Bar row = %q", got)
+	}
+	if got := lines[7]; !strings.HasSuffix(strings.TrimRight(got, " "), " 7 |") {
+		t.Errorf("Stack count row = %q", got)
+	}
+	if !strings.Contains(lines[3], "Off: 13") || !strings.Contains(lines[9], "Off: 7") {
+		t.Errorf("Borne-off counts missing:\n%s", strings.Join(lines, "\n"))
+	}
+}
@@ -0,0 +1,339 @@
+package gnubgparser
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Starting points of each variation, as (point index, checkers) from each
+// player's own point of view
+var startingPoints = map[string][][2]int{
+	"Standard":     {{23, 2}, {12, 5}, {7, 3}, {5, 5}},
+	"Nackgammon":   {{23, 2}, {22, 2}, {12, 4}, {7, 3}, {5, 4}},
+	"Hypergammon1": {{23, 1}},
+	"Hypergammon2": {{23, 1}, {22, 1}},
+	"Hypergammon3": {{23, 1}, {22, 1}, {21, 1}},
+}
+
+// startingBoard returns the initial checker layout of a variation
+func startingBoard(variation string) [2][25]int {
+	points, ok := startingPoints[variation]
+	if !ok {
+		points = startingPoints["Standard"]
+	}
+	var board [2][25]int
+	for p := 0; p < 2; p++ {
+		for _, pt := range points {
+			board[p][pt[0]] = pt[1]
+		}
+	}
+	return board
+}
+
+// moveHops returns the checker hops of a checker play from the mover's
+// point of view: 0-23 for points 1-24, 24 for the bar and -1 for off.
+// MAT records carry them as SubMoves; SGF moves are decoded from the
+// gnuBG encoding, where player 1's points are numbered from the other side.
+func moveHops(move [8]int, subMoves []SubMove, player int) []SubMove {
+	if subMoves != nil {
+		hops := make([]SubMove, len(subMoves))
+		copy(hops, subMoves)
+		return hops
+	}
+
+	var hops []SubMove
+	for i := 0; i < 8; i += 2 {
+		if move[i] < 0 {
+			break
+		}
+		hops = append(hops, SubMove{From: sgfPoint(move[i], player), To: sgfPoint(move[i+1], player)})
+	}
+	return hops
+}
+
+// Hops returns the checker hops of a checker play from the mover's point
+// of view (see SubMove), whichever format the record came from
+func (mr *MoveRecord) Hops() []SubMove {
+	if mr.Type != MoveTypeNormal {
+		return nil
+	}
+	return moveHops(mr.Move, mr.SubMoves, mr.Player)
+}
+
+// sgfPoint converts an SGF move point to the mover's point of view
+func sgfPoint(point, player int) int {
+	switch {
+	case point == 24:
+		return 24
+	case point >= 25 || point < 0:
+		return -1
+	case player == 1:
+		return 23 - point
+	}
+	return point
+}
+
+// applyMove moves player's checkers along hops, sending hit blots to the
+// bar. It returns the hops with Hit set where a blot was hit.
+func (p *Position) applyMove(player int, hops []SubMove) []SubMove {
+	played := make([]SubMove, len(hops))
+	for i, hop := range hops {
+		hop.Hit = false
+		if hop.From >= 0 && hop.From <= 24 && p.Board[player][hop.From] > 0 {
+			p.Board[player][hop.From]--
+		}
+		if hop.To >= 0 && hop.To < 24 {
+			if opp := 23 - hop.To; p.Board[1-player][opp] == 1 {
+				p.Board[1-player][opp] = 0
+				p.Board[1-player][24]++
+				hop.Hit = true
+			}
+			p.Board[player][hop.To]++
+		}
+		played[i] = hop
+	}
+	return played
+}
+
+// PipCount returns the pip count of a player
+func (p *Position) PipCount(player int) int {
+	pips := 0
+	for i, n := range p.Board[player] {
+		pips += (i + 1) * n
+	}
+	return pips
+}
+
+// BorneOff returns the number of checkers a player has borne off, given
+// the number of checkers each player starts with
+func (p *Position) BorneOff(player, checkers int) int {
+	on := 0
+	for _, n := range p.Board[player] {
+		on += n
+	}
+	if on > checkers {
+		return 0
+	}
+	return checkers - on
+}
+
+// GamePositions returns the position before each move record of a game,
+// replayed from the starting position of its variation, setboard records
+// and the cube actions. The player on roll is the one making the decision
+// at that record, except at take and drop records where the doubler stays
+// on roll. Dice are set for checker plays.
+func GamePositions(game *Game, matchLength int) []Position {
+	positions := make([]Position, len(game.Moves))
+	cubes, _, _ := replayCube(game)
+	pos := Position{
+		Board:       startingBoard(game.Variation),
+		Score:       game.Score,
+		MatchLength: matchLength,
+		Crawford:    game.CrawfordGame,
+	}
+
+	for i := range game.Moves {
+		mr := &game.Moves[i]
+		if mr.Type == MoveTypeSetBoard && mr.Position != nil {
+			pos.Board = mr.Position.Board
+		}
+
+		pos.CubeValue = cubes[i].Value
+		pos.CubeOwner = cubes[i].Owner
+		pos.Dice = [2]int{}
+		switch mr.Type {
+		case MoveTypeNormal, MoveTypeDouble, MoveTypeResign:
+			pos.OnRoll = mr.Player
+		case MoveTypeTake, MoveTypeDrop:
+			pos.OnRoll = 1 - mr.Player
+		case MoveTypeSetBoard:
+			if mr.Position != nil {
+				pos.OnRoll = mr.Position.OnRoll
+			}
+		}
+		if mr.Type == MoveTypeNormal {
+			pos.Dice = mr.Dice
+		}
+		positions[i] = pos
+
+		if mr.Type == MoveTypeNormal && (mr.Player == 0 || mr.Player == 1) {
+			pos.applyMove(mr.Player, moveHops(mr.Move, mr.SubMoves, mr.Player))
+		}
+	}
+	return positions
+}
+
+// finalPosition returns the position after the last record of a game,
+// given its GamePositions
+func finalPosition(game *Game, positions []Position, matchLength int) Position {
+	if len(positions) == 0 {
+		return Position{Board: startingBoard(game.Variation), Score: game.Score,
+			MatchLength: matchLength, Crawford: game.CrawfordGame, CubeValue: 1, CubeOwner: -1}
+	}
+	last := len(positions) - 1
+	pos := positions[last]
+	if mr := &game.Moves[last]; mr.Type == MoveTypeNormal && (mr.Player == 0 || mr.Player == 1) {
+		pos.applyMove(mr.Player, mr.Hops())
+	}
+	pos.Dice = [2]int{}
+	return pos
+}
+
+// formatHops writes hops in the usual notation, e.g. "bar/22* 13/9(2)
+// 24/20*/16 6/off": chained hops of one checker are merged, keeping the
+// points where they hit, and identical moves are counted
+func formatHops(hops []SubMove) string {
+	if len(hops) == 0 {
+		return "Cannot Move"
+	}
+
+	// Merge chains: a hop continuing from where another one landed
+	chains := make([][]SubMove, 0, len(hops))
+	for _, hop := range hops {
+		merged := false
+		for c := range chains {
+			last := chains[c][len(chains[c])-1]
+			if last.To == hop.From {
+				chains[c] = append(chains[c], hop)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			chains = append(chains, []SubMove{hop})
+		}
+	}
+
+	parts := make([]string, 0, len(chains))
+	for _, chain := range chains {
+		s := hopPoint(chain[0].From)
+		for i, hop := range chain {
+			if hop.Hit || i == len(chain)-1 {
+				s += "/" + hopPoint(hop.To)
+				if hop.Hit {
+					s += "*"
+				}
+			}
+		}
+		parts = append(parts, s)
+	}
+	sort.SliceStable(parts, func(a, b int) bool {
+		return hopSortKey(parts[a]) > hopSortKey(parts[b])
+	})
+
+	var out []string
+	for i := 0; i < len(parts); {
+		n := 1
+		for i+n < len(parts) && parts[i+n] == parts[i] {
+			n++
+		}
+		if n > 1 {
+			out = append(out, fmt.Sprintf("%s(%d)", parts[i], n))
+		} else {
+			out = append(out, parts[i])
+		}
+		i += n
+	}
+	return strings.Join(out, " ")
+}
+
+// hopPoint names a point in move notation
+func hopPoint(point int) string {
+	switch {
+	case point == 24:
+		return "bar"
+	case point < 0:
+		return "off"
+	}
+	return fmt.Sprint(point + 1)
+}
+
+// hopSortKey orders moves by their starting point, the bar first
+func hopSortKey(part string) int {
+	from := strings.SplitN(part, "/", 2)[0]
+	if from == "bar" {
+		return 25
+	}
+	var n int
+	fmt.Sscan(from, &n)
+	return n
+}
+
+// PositionID returns gnuBG's Position ID: the checkers of the player not
+// on roll, then of the player on roll, as runs of bits in base64
+func (p *Position) PositionID() string {
+	var key [10]byte
+	bit := 0
+	for _, player := range []int{1 - p.OnRoll, p.OnRoll} {
+		for _, n := range p.Board[player] {
+			for ; n > 0 && bit < 80; n-- {
+				key[bit/8] |= 1 << (bit % 8)
+				bit++
+			}
+			bit++
+		}
+	}
+	return base64.RawStdEncoding.EncodeToString(key[:])
+}
+
+// matchIDState describes the parts of gnuBG's Match ID that are not part
+// of a Position
+type matchIDState struct {
+	Turn     int  // Player who has to act
+	Doubled  bool // A double is pending
+	Resigned int  // Pending resignation level
+}
+
+// MatchID returns gnuBG's Match ID for a position where the player on roll
+// has to act. Player 0 (Player1) is gnuBG's player 1, as in SGF files
+// where White is player 1.
+func (p *Position) MatchID() string {
+	return p.matchID(matchIDState{Turn: p.OnRoll})
+}
+
+// matchID encodes the Match ID with the given turn state
+func (p *Position) matchID(state matchIDState) string {
+	var key [9]byte
+	bit := 0
+	put := func(value, width int) {
+		for i := 0; i < width; i++ {
+			if value&(1<<i) != 0 {
+				key[bit/8] |= 1 << (bit % 8)
+			}
+			bit++
+		}
+	}
+	gnubg := func(player int) int { return 1 - player }
+	flag := func(b bool) int {
+		if b {
+			return 1
+		}
+		return 0
+	}
+
+	cubeLog := 0
+	for v := p.CubeValue; v > 1; v /= 2 {
+		cubeLog++
+	}
+	owner := 3
+	if p.CubeOwner == 0 || p.CubeOwner == 1 {
+		owner = gnubg(p.CubeOwner)
+	}
+
+	put(cubeLog, 4)
+	put(owner, 2)
+	put(gnubg(p.OnRoll), 1)
+	put(flag(p.Crawford), 1)
+	put(1, 3) // Game in progress
+	put(gnubg(state.Turn), 1)
+	put(flag(state.Doubled), 1)
+	put(state.Resigned, 2)
+	put(p.Dice[0], 3)
+	put(p.Dice[1], 3)
+	put(p.MatchLength, 15)
+	put(p.Score[1], 15)
+	put(p.Score[0], 15)
+	return base64.RawStdEncoding.EncodeToString(key[:])
+}
@@ -0,0 +1,67 @@
+package gnubgparser
+
+import "testing"
+
+func TestPositionIDs(t *testing.T) {
+	pos := Position{Board: startingBoard("Standard"), CubeValue: 1, CubeOwner: -1}
+	if got := pos.PositionID(); got != "4HPwATDgc/ABMA" {
+		t.Errorf("Starting PositionID = %s, want 4HPwATDgc/ABMA", got)
+	}
+	if got := pos.MatchID(); got != "cAkAAAAAAAAA" {
+		t.Errorf("Starting MatchID = %s, want cAkAAAAAAAAA", got)
+	}
+
+	pos.applyMove(0, []SubMove{{From: 7, To: 4}, {From: 5, To: 4}})
+	pos.OnRoll = 1
+	if got := pos.PositionID(); got != "sGfwATDgc/ABMA" {
+		t.Errorf("PositionID after 8/5 6/5 = %s, want sGfwATDgc/ABMA", got)
+	}
+	if pos.PipCount(0) != 163 || pos.PipCount(1) != 167 {
+		t.Errorf("Pip counts = %d/%d, want 163/167", pos.PipCount(0), pos.PipCount(1))
+	}
+}
+
+func TestFormatHops(t *testing.T) {
+	tests := []struct {
+		name string
+		hops []SubMove
+		want string
+	}{
+		{"no move", nil, "Cannot Move"},
+		{"sorted", []SubMove{{From: 5, To: 4}, {From: 7, To: 4}}, "8/5 6/5"},
+		{"chain", []SubMove{{From: 23, To: 19}, {From: 19, To: 15}}, "24/16"},
+		{"hit on the way", []SubMove{{From: 23, To: 19, Hit: true}, {From: 19, To: 15}}, "24/20*/16"},
+		{"doubles", []SubMove{{From: 12, To: 10}, {From: 12, To: 10}, {From: 5, To: 3}, {From: 5, To: 3}}, "13/11(2) 6/4(2)"},
+		{"bar and off", []SubMove{{From: 24, To: 21, Hit: true}, {From: 2, To: -1}}, "bar/22* 3/off"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatHops(tt.hops); got != tt.want {
+				t.Errorf("formatHops() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayPositionsMatchesMAT(t *testing.T) {
+	sgf, err := ParseSGFFile("test/charlot1-charlot2_7p_2025-11-08-2305.sgf")
+	if err != nil {
+		t.Fatalf("Failed to parse SGF: %v", err)
+	}
+	mat, err := ParseMATFile("test/charlot1-charlot2_7p_2025-11-08-2305.mat")
+	if err != nil {
+		t.Fatalf("Failed to parse MAT: %v", err)
+	}
+
+	// Both files hold the same match: SGF moves are numbered from White's
+	// side, MAT moves from the mover's, and must give the same boards
+	for g := range sgf.Games {
+		a := GamePositions(&sgf.Games[g], 7)
+		b := GamePositions(&mat.Games[g], 7)
+		for i := 0; i < len(a) && i < len(b); i++ {
+			if a[i].Board != b[i].Board {
+				t.Fatalf("Game %d move %d: boards differ\nSGF:\n%s\nMAT:\n%s", g+1, i+1, &a[i], &b[i])
+			}
+		}
+	}
+}
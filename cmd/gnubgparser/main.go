@@ -1,24 +1,47 @@
 // gnubgparser command-line tool
 //
-// Parse gnuBG SGF match files and output JSON or summary information.
+// Parse gnuBG SGF match files and output JSON, CSV, HTML, text or summary information.
 //
 // Usage:
 //   gnubgparser <file.sgf>              - Parse and output JSON
 //   gnubgparser -format=summary <file.sgf> - Show match summary
+//   gnubgparser -format=csv <file.sgf>  - One CSV row per move record
+//   gnubgparser -format=html <file.sgf> - Single-file HTML analysis report
+//   gnubgparser -format=replay <file.sgf> - Single-file interactive HTML replayer
+//   gnubgparser -format=text <file.sgf> - gnuBG-style text export
+//   gnubgparser -format=markdown <file.sgf> - Markdown report for wikis
+//   gnubgparser -format=latex -rating=bad <file.sgf> - LaTeX/TikZ figures of the errors
+//   gnubgparser validate <file.sgf>     - Check match consistency
+//   gnubgparser board <file.sgf> <game> <move> - Draw the board before a move
+//   gnubgparser -format=svg board <file.sgf> <game> <move> - Same as SVG (or png)
+//   gnubgparser gif <file.sgf> <game> > game.gif - Animated replay of a game
+//   gnubgparser view <file.sgf>         - Step through the match in the terminal
+//   gnubgparser -match=2 <file.mat>     - Use only the second match of a file
 
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/kevung/gnubgparser"
 )
 
 var (
-	formatFlag = flag.String("format", "json", "Output format: json, summary")
+	formatFlag = flag.String("format", "json", "Output format: json, csv, html, replay, text, markdown, latex, summary (svg, png for board)")
+	bottomFlag = flag.Int("bottom", 1, "Player drawn at the bottom of boards (1 or 2)")
+	sizeFlag   = flag.Int("size", 600, "Width in pixels of svg, png and gif boards")
+	arrowsFlag = flag.Bool("arrows", false, "Draw the played move as arrows on svg and png boards")
+	ratingFlag = flag.String("rating", "", "Minimum rating of decisions in latex output: doubtful, bad or verybad")
+	delayFlag  = flag.Duration("delay", 1500*time.Millisecond, "Time each move is shown in gif replays")
+	metFlag    = flag.String("met", "", "gnuBG match equity table file (default Kazaross-XG2)")
+	matchFlag  = flag.Int("match", 0, "Use only this match (1-based) of files holding several matches")
 )
 
 func main() {
@@ -26,6 +49,10 @@ func main() {
 
 	if flag.NArg() < 1 {
 		fmt.Fprintf(os.Stderr, "Usage: %s [options] <file.sgf|file.mat>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s validate <file.sgf|file.mat>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s board <file.sgf|file.mat> <game> <move>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s gif <file.sgf|file.mat> <game>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s view <file.sgf|file.mat>\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\nOptions:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nSupported formats:\n")
@@ -34,43 +61,253 @@ func main() {
 		os.Exit(1)
 	}
 
+	command := ""
 	filename := flag.Arg(0)
+	var args []string
+	switch filename {
+	case "validate", "board", "gif", "view":
+		if flag.NArg() > 1 {
+			command = filename
+			filename = flag.Arg(1)
+			args = flag.Args()[2:]
+		}
+	}
 
-	// Determine file type and parse accordingly
-	var match *gnubgparser.Match
+	// Determine file type and parse accordingly; a file may hold several
+	// matches
+	var matches []*gnubgparser.Match
 	var err error
 
 	if len(filename) > 4 && filename[len(filename)-4:] == ".mat" {
 		// Parse MAT file
-		match, err = gnubgparser.ParseMATFile(filename)
+		matches, err = gnubgparser.ParseAllMATFile(filename)
 		if err != nil {
 			log.Fatalf("Error parsing MAT file: %v\n", err)
 		}
 	} else {
 		// Parse SGF file (default)
-		match, err = gnubgparser.ParseSGFFile(filename)
+		matches, err = gnubgparser.ParseAllSGFFile(filename)
 		if err != nil {
 			log.Fatalf("Error parsing SGF file: %v\n", err)
 		}
 	}
 
+	if *matchFlag != 0 {
+		if *matchFlag < 1 || *matchFlag > len(matches) {
+			log.Fatalf("Invalid match %d (the file has %d matches)\n", *matchFlag, len(matches))
+		}
+		matches = matches[*matchFlag-1 : *matchFlag]
+	}
+
+	if *metFlag != "" {
+		met, err := gnubgparser.ReadMETFile(*metFlag)
+		if err != nil {
+			log.Fatalf("Error reading match equity table: %v\n", err)
+		}
+		for _, match := range matches {
+			match.MET = met
+			gnubgparser.RateMatch(match, gnubgparser.DefaultRatingOptions())
+		}
+	}
+
+	if command == "validate" {
+		validate(matches)
+		return
+	}
+
+	// The other commands and formats show a single match
+	only := func() *gnubgparser.Match {
+		if len(matches) != 1 {
+			log.Fatalf("%s holds %d matches; choose one with -match\n", filename, len(matches))
+		}
+		return matches[0]
+	}
+
+	if command == "board" {
+		printBoard(only(), args)
+		return
+	}
+	if command == "gif" {
+		writeGIF(only(), args)
+		return
+	}
+	if command == "view" {
+		runView(only())
+		return
+	}
+
 	// Output based on format
 	switch *formatFlag {
 	case "json":
-		jsonData, err := match.ToJSON()
+		var jsonData []byte
+		if len(matches) == 1 {
+			jsonData, err = matches[0].ToJSON()
+		} else {
+			jsonData, err = json.MarshalIndent(matches, "", "  ")
+		}
 		if err != nil {
 			log.Fatalf("Error converting to JSON: %v\n", err)
 		}
 		fmt.Println(string(jsonData))
 
+	case "csv":
+		if err := gnubgparser.WriteCSV(os.Stdout, matches...); err != nil {
+			log.Fatalf("Error writing CSV: %v\n", err)
+		}
+
+	case "html":
+		if err := gnubgparser.WriteHTML(os.Stdout, only()); err != nil {
+			log.Fatalf("Error writing HTML: %v\n", err)
+		}
+
+	case "replay":
+		if err := gnubgparser.WriteHTMLReplay(os.Stdout, only()); err != nil {
+			log.Fatalf("Error writing HTML replay: %v\n", err)
+		}
+
+	case "markdown":
+		if err := gnubgparser.WriteMarkdown(os.Stdout, only()); err != nil {
+			log.Fatalf("Error writing Markdown: %v\n", err)
+		}
+
+	case "latex":
+		ratings := map[string]string{"": "", "doubtful": "Doubtful", "bad": "Bad", "verybad": "VeryBad"}
+		rating, ok := ratings[strings.ToLower(*ratingFlag)]
+		if !ok {
+			log.Fatalf("Unknown rating: %s\n", *ratingFlag)
+		}
+		opts := gnubgparser.LaTeXOptions{
+			Diagram:   gnubgparser.DiagramOptions{Bottom: *bottomFlag - 1},
+			MinRating: rating,
+		}
+		if err := gnubgparser.WriteLaTeX(os.Stdout, only(), opts); err != nil {
+			log.Fatalf("Error writing LaTeX: %v\n", err)
+		}
+
+	case "text":
+		if err := gnubgparser.WriteText(os.Stdout, only()); err != nil {
+			log.Fatalf("Error writing text: %v\n", err)
+		}
+
 	case "summary":
-		printSummary(match)
+		for i, match := range matches {
+			if i > 0 {
+				fmt.Println()
+			}
+			printSummary(match)
+		}
 
 	default:
 		log.Fatalf("Unknown format: %s\n", *formatFlag)
 	}
 }
 
+// runView opens the terminal viewer, reading single keys when the terminal
+// can be put in raw mode
+func runView(match *gnubgparser.Match) {
+	restore, err := makeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot read single keys (%v); press Enter after each key\n", err)
+		restore = func() {}
+	}
+	err = gnubgparser.View(os.Stdin, os.Stdout, match, gnubgparser.ViewOptions{Bottom: *bottomFlag - 1})
+	restore()
+	if err != nil {
+		log.Fatalf("Error: %v\n", err)
+	}
+}
+
+// writeGIF writes the animated replay of a game, given its 1-based number
+func writeGIF(match *gnubgparser.Match, args []string) {
+	if len(args) != 1 {
+		log.Fatalf("Usage: gif <file> <game>\n")
+	}
+	gameNum, err := strconv.Atoi(args[0])
+	if err != nil || gameNum < 1 || gameNum > len(match.Games) {
+		log.Fatalf("Invalid game %q (the match has %d games)\n", args[0], len(match.Games))
+	}
+
+	opts := gnubgparser.GIFOptions{
+		Diagram: gnubgparser.DiagramOptions{
+			Width:  *sizeFlag,
+			Bottom: *bottomFlag - 1,
+			Names:  [2]string{match.Metadata.Player1, match.Metadata.Player2},
+		},
+		Delay: int(*delayFlag / (10 * time.Millisecond)),
+	}
+	if err := gnubgparser.WriteGameGIF(os.Stdout, &match.Games[gameNum-1], match.Metadata.MatchLength, opts); err != nil {
+		log.Fatalf("Error writing gif: %v\n", err)
+	}
+}
+
+// printBoard draws the position before a move record, given the 1-based
+// game and move numbers used by the text export, as text or as an svg or
+// png image depending on -format
+func printBoard(match *gnubgparser.Match, args []string) {
+	if len(args) != 2 {
+		log.Fatalf("Usage: board <file> <game> <move>\n")
+	}
+	gameNum, err1 := strconv.Atoi(args[0])
+	moveNum, err2 := strconv.Atoi(args[1])
+	if err1 != nil || err2 != nil || gameNum < 1 || gameNum > len(match.Games) {
+		log.Fatalf("Invalid game %q (the match has %d games)\n", args[0], len(match.Games))
+	}
+	game := &match.Games[gameNum-1]
+	if moveNum < 1 || moveNum > len(game.Moves) {
+		log.Fatalf("Invalid move %q (game %d has %d moves)\n", args[1], gameNum, len(game.Moves))
+	}
+
+	positions := gnubgparser.GamePositions(game, match.Metadata.MatchLength)
+	pos := positions[moveNum-1]
+	mr := game.Moves[moveNum-1]
+	names := [2]string{match.Metadata.Player1, match.Metadata.Player2}
+
+	if *formatFlag == "svg" || *formatFlag == "png" {
+		opts := gnubgparser.DiagramOptions{Width: *sizeFlag, Bottom: *bottomFlag - 1, Names: names}
+		if *arrowsFlag {
+			opts.Move = mr.Hops()
+		}
+		render := pos.RenderSVG
+		if *formatFlag == "png" {
+			render = pos.RenderPNG
+		}
+		if err := render(os.Stdout, opts); err != nil {
+			log.Fatalf("Error writing %s: %v\n", *formatFlag, err)
+		}
+		return
+	}
+
+	fmt.Print(pos.RenderASCII(gnubgparser.ASCIIOptions{
+		Bottom: *bottomFlag - 1,
+		Names:  names,
+	}))
+	fmt.Printf("Position ID: %s  Match ID: %s\n", pos.PositionID(), pos.MatchID())
+
+	if mr.MoveString != "" {
+		fmt.Printf("Played: %s\n", mr.MoveString)
+	}
+}
+
+// validate lists the consistency issues of the matches, prefixed with the
+// match number when there are several, and exits with status 1 if any
+func validate(matches []*gnubgparser.Match) {
+	count := 0
+	for i, match := range matches {
+		for _, issue := range match.Validate() {
+			if len(matches) > 1 {
+				fmt.Printf("match %d: ", i+1)
+			}
+			fmt.Println(issue)
+			count++
+		}
+	}
+	if count > 0 {
+		fmt.Fprintf(os.Stderr, "%d issue(s) found\n", count)
+		os.Exit(1)
+	}
+	fmt.Println("OK")
+}
+
 func printSummary(match *gnubgparser.Match) {
 	fmt.Println("=== Match Summary ===")
 	fmt.Printf("Players: %s vs %s\n", match.Metadata.Player1, match.Metadata.Player2)
@@ -101,8 +338,42 @@ func printSummary(match *gnubgparser.Match) {
 		fmt.Printf("Application: %s\n", match.Metadata.Application)
 	}
 
+	perf := gnubgparser.MatchPerformance(match)
+	if perf.Players[0].UnforcedDecisions()+perf.Players[1].UnforcedDecisions() > 0 {
+		fmt.Println("\nPerformance:")
+		names := [2]string{match.Metadata.Player1, match.Metadata.Player2}
+		for i, p := range perf.Players {
+			fmt.Printf("  %s: PR %.2f (checker %.2f, cube %.2f), Snowie error rate %.2f\n",
+				names[i], p.PR(), p.CheckerPR(), p.CubePR(), perf.SnowieErrorRate(i))
+		}
+	}
+
+	luck := gnubgparser.MatchLuck(match)
+	if luck.Players[0].Rolls+luck.Players[1].Rolls > 0 {
+		fmt.Println("\nLuck:")
+		names := [2]string{match.Metadata.Player1, match.Metadata.Player2}
+		for i, l := range luck.Players {
+			fmt.Printf("  %s: %+.3f (%d jokers, %d anti-jokers), luck-adjusted result %.3f\n",
+				names[i], l.Total, l.Jokers, l.AntiJokers, luck.LuckAdjusted[i])
+		}
+	}
+
 	fmt.Printf("\nGames: %d\n", len(match.Games))
 
+	stats := match.Statistics()
+	fmt.Printf("Final Score: %d-%d\n", stats.FinalScore[0], stats.FinalScore[1])
+	if stats.Winner >= 0 {
+		winner := match.Metadata.Player1
+		if stats.Winner == 1 {
+			winner = match.Metadata.Player2
+		}
+		fmt.Printf("Match Winner: %s\n", winner)
+	}
+	if stats.Gammons != [2]int{} || stats.Backgammons != [2]int{} {
+		fmt.Printf("Gammons: %d-%d, Backgammons: %d-%d\n",
+			stats.Gammons[0], stats.Gammons[1], stats.Backgammons[0], stats.Backgammons[1])
+	}
+
 	for i, game := range match.Games {
 		fmt.Printf("\n--- Game %d ---\n", i+1)
 		fmt.Printf("Score: %d-%d\n", game.Score[0], game.Score[1])
@@ -122,6 +393,9 @@ func printSummary(match *gnubgparser.Match) {
 			}
 			fmt.Println()
 		}
+		if game.ResultKind != "" {
+			fmt.Printf("Result: %s\n", game.ResultKind)
+		}
 
 		if game.Crawford {
 			fmt.Println("Crawford rule: enabled")
@@ -129,6 +403,9 @@ func printSummary(match *gnubgparser.Match) {
 		if game.CrawfordGame {
 			fmt.Println("This is the Crawford game")
 		}
+		if game.PostCrawford {
+			fmt.Println("Post-Crawford game")
+		}
 		if game.Jacoby {
 			fmt.Println("Jacoby rule: enabled")
 		}
@@ -2,6 +2,8 @@ package gnubgparser
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -25,14 +27,108 @@ func convertNodesToMatch(nodes []*SGFNode) (*Match, error) {
 		match.Games = append(match.Games, *game)
 	}
 
+	match.Warnings = append(match.Warnings, deriveCrawford(match, true)...)
+	finishSGFMatch(match)
+
 	return match, nil
 }
 
+// convertNodesToMatches converts parsed SGF nodes into one Match per match,
+// splitting whenever a game tree starts a new match
+func convertNodesToMatches(nodes []*SGFNode) ([]*Match, error) {
+	var matches []*Match
+	var match *Match
+	var prev sgfMatchKey
+
+	for _, gameNode := range nodes {
+		key := newSGFMatchKey(gameNode)
+		if match == nil || key.startsNewMatch(prev) {
+			match = &Match{Games: make([]Game, 0)}
+			matches = append(matches, match)
+		}
+		prev = key.inherit(prev)
+
+		game, err := convertGame(gameNode, match)
+		if err != nil {
+			return nil, err
+		}
+		match.Games = append(match.Games, *game)
+	}
+
+	for _, match := range matches {
+		match.Warnings = append(match.Warnings, deriveCrawford(match, true)...)
+		finishSGFMatch(match)
+	}
+
+	return matches, nil
+}
+
+// sgfMatchKey holds the root properties identifying the match a game
+// tree belongs to
+type sgfMatchKey struct {
+	player1, player2 string
+	length           int
+	hasLength        bool
+	game             int
+}
+
+// newSGFMatchKey reads the match identity from a game tree's root node
+func newSGFMatchKey(root *SGFNode) sgfMatchKey {
+	key := sgfMatchKey{
+		player1: getProperty(root, "PW"),
+		player2: getProperty(root, "PB"),
+	}
+
+	if values, ok := root.Properties["MI"]; ok && len(values) > 0 {
+		var m Match
+		var g Game
+		parseMatchInfo(strings.Join(values, "]["), &m, &g)
+		key.length = m.Metadata.MatchLength
+		key.hasLength = true
+		key.game = g.GameNumber
+	}
+
+	return key
+}
+
+// startsNewMatch reports whether a game tree with this key cannot belong
+// to the same match as the previous game tree
+func (k sgfMatchKey) startsNewMatch(prev sgfMatchKey) bool {
+	if k.player1 != "" && prev.player1 != "" && k.player1 != prev.player1 {
+		return true
+	}
+	if k.player2 != "" && prev.player2 != "" && k.player2 != prev.player2 {
+		return true
+	}
+	if k.hasLength && prev.hasLength {
+		if k.length != prev.length || k.game <= prev.game {
+			return true
+		}
+	}
+	return false
+}
+
+// inherit fills properties missing from this key with the previous game's,
+// so a game tree without PW/PB or MI does not reset the comparison
+func (k sgfMatchKey) inherit(prev sgfMatchKey) sgfMatchKey {
+	if k.player1 == "" {
+		k.player1 = prev.player1
+	}
+	if k.player2 == "" {
+		k.player2 = prev.player2
+	}
+	if !k.hasLength {
+		k.length, k.hasLength, k.game = prev.length, prev.hasLength, prev.game
+	}
+	return k
+}
+
 // convertGame converts an SGF game tree to a Game structure
 func convertGame(root *SGFNode, match *Match) (*Game, error) {
 	game := &Game{
 		Moves:       make([]MoveRecord, 0),
 		CubeEnabled: true,
+		Winner:      -1,
 	}
 
 	// Extract match/game metadata from root node
@@ -55,9 +151,30 @@ func convertGame(root *SGFNode, match *Match) (*Game, error) {
 		}
 	}
 
+	// gnuBG records an accepted resignation only as the R suffix of RE:
+	// restore the resignation and its acceptance as move records
+	if game.Resigned && game.Winner >= 0 && lastResignation(game) == nil {
+		game.Moves = append(game.Moves,
+			MoveRecord{Type: MoveTypeResign, Player: 1 - game.Winner},
+			MoveRecord{Type: MoveTypeAccept, Player: game.Winner},
+		)
+	}
+
 	return game, nil
 }
 
+// finishSGFMatch checks the cube and results of a converted match and
+// rates its games. It runs once the Crawford games are derived from the
+// score sequence, as the conversions to EMG depend on them.
+func finishSGFMatch(match *Match) {
+	checkGames(match)
+	for i := range match.Games {
+		game := &match.Games[i]
+		rateGame(game, match.Metadata.MatchLength, DefaultRatingOptions(), match.MET)
+		game.Statistics = computeGameStatistic(game, match.Metadata.MatchLength, match.MET)
+	}
+}
+
 // extractMetadata extracts metadata from the root node
 func extractMetadata(node *SGFNode, match *Match, game *Game) error {
 	// SGF format info
@@ -114,9 +231,9 @@ func extractMetadata(node *SGFNode, match *Match, game *Game) error {
 		parseRules(ru, game)
 	}
 
-	// Cube value
+	// Starting cube value, 2^n after n automatic doubles
 	if cv := getProperty(node, "CV"); cv != "" {
-		game.AutoDoubles = getPropertyInt(node, "CV")
+		game.AutoDoubles = autoDoublesFromCubeValue(getPropertyInt(node, "CV"))
 	}
 
 	// Result
@@ -124,6 +241,11 @@ func extractMetadata(node *SGFNode, match *Match, game *Game) error {
 		parseResult(re, game)
 	}
 
+	// Statistics
+	if gs, ok := node.Properties["GS"]; ok {
+		game.recorded = parseGameStatistic(gs)
+	}
+
 	return nil
 }
 
@@ -273,12 +395,12 @@ func processNode(node *SGFNode, game *Game) error {
 		}
 		mr.Dice[0], _ = strconv.Atoi(string(di[0]))
 		mr.Dice[1], _ = strconv.Atoi(string(di[1]))
-		game.Moves = append(game.Moves, mr)
 
 		// Check for luck rating
 		if hasProperty(node, "LU") {
 			parseLuck(node, &mr)
 		}
+		game.Moves = append(game.Moves, mr)
 	}
 
 	// Check for player on roll (PL property)
@@ -302,6 +424,16 @@ func processMove(node *SGFNode, game *Game, player int, moveStr string, comment
 		mr.Type = MoveTypeTake
 	} else if moveStr == "drop" || moveStr == "pass" {
 		mr.Type = MoveTypeDrop
+	} else if fields := strings.Fields(moveStr); len(fields) > 0 && fields[0] == "resign" {
+		// Resignation offer: B[resign], B[resign gammon] or B[resign 2]
+		mr.Type = MoveTypeResign
+		if len(fields) > 1 {
+			mr.ResignLevel = parseResignLevel(fields[1])
+		}
+	} else if moveStr == "accept" {
+		mr.Type = MoveTypeAccept
+	} else if moveStr == "reject" {
+		mr.Type = MoveTypeReject
 	} else {
 		// Normal move: dice + encoded move
 		mr.Type = MoveTypeNormal
@@ -361,6 +493,12 @@ func processMove(node *SGFNode, game *Game, player int, moveStr string, comment
 					Player2WinRate:        mr.CubeAnalysis.Player2WinRate,
 					Player2GammonRate:     mr.CubeAnalysis.Player2GammonRate,
 					Player2BackgammonRate: mr.CubeAnalysis.Player2BackgammonRate,
+					OnRollWin:             mr.CubeAnalysis.OnRollWin,
+					OnRollGammon:          mr.CubeAnalysis.OnRollGammon,
+					OnRollBackgammon:      mr.CubeAnalysis.OnRollBackgammon,
+					OpponentWin:           mr.CubeAnalysis.OpponentWin,
+					OpponentGammon:        mr.CubeAnalysis.OpponentGammon,
+					OpponentBackgammon:    mr.CubeAnalysis.OpponentBackgammon,
 					AnalysisDepth:         mr.CubeAnalysis.AnalysisDepth,
 				},
 			},
@@ -368,10 +506,122 @@ func processMove(node *SGFNode, game *Game, player int, moveStr string, comment
 		}
 	}
 
+	if mr.Type == MoveTypeNormal && mr.Analysis != nil {
+		selectPlayedMove(&mr)
+	}
+
 	game.Moves = append(game.Moves, mr)
 	return nil
 }
 
+// selectPlayedMove finds the played move among the analysed options and
+// sets SelectedMove and EquityLoss. Submoves are compared regardless of
+// order; if no option has the same submoves, options with the same net
+// checker movement (chained hops merged) are accepted.
+func selectPlayedMove(mr *MoveRecord) {
+	analysis := mr.Analysis
+	analysis.SelectedMove = SelectedMoveUnknown
+	analysis.EquityLoss = 0
+	if len(analysis.Moves) == 0 {
+		return
+	}
+
+	played := normalizeSubMoves(mr.Move)
+	for i, opt := range analysis.Moves {
+		if equalSubMoves(played, normalizeSubMoves(opt.Move)) {
+			analysis.SelectedMove = i
+			break
+		}
+	}
+	if analysis.SelectedMove < 0 {
+		playedNet := netMovement(mr.Move)
+		for i, opt := range analysis.Moves {
+			if equalSubMoves(playedNet, netMovement(opt.Move)) {
+				analysis.SelectedMove = i
+				break
+			}
+		}
+	}
+	if analysis.SelectedMove < 0 {
+		return
+	}
+
+	best := analysis.Moves[0].Equity
+	for _, opt := range analysis.Moves[1:] {
+		if opt.Equity > best {
+			best = opt.Equity
+		}
+	}
+	analysis.EquityLoss = best - analysis.Moves[analysis.SelectedMove].Equity
+}
+
+// normalizeSubMoves returns the from/to pairs of an encoded move, sorted
+func normalizeSubMoves(move [8]int) [][2]int {
+	var pairs [][2]int
+	for i := 0; i < 8; i += 2 {
+		if move[i] < 0 {
+			break
+		}
+		pairs = append(pairs, [2]int{move[i], move[i+1]})
+	}
+	sort.Slice(pairs, func(a, b int) bool {
+		if pairs[a][0] != pairs[b][0] {
+			return pairs[a][0] > pairs[b][0]
+		}
+		return pairs[a][1] > pairs[b][1]
+	})
+	return pairs
+}
+
+// netMovement merges chained hops of an encoded move (e.g. 24/20 20/16
+// becomes 24/16), so moves written with different intermediate points
+// compare equal
+func netMovement(move [8]int) [][2]int {
+	pairs := normalizeSubMoves(move)
+	for merged := true; merged; {
+		merged = false
+		for i := range pairs {
+			for j := range pairs {
+				if i != j && pairs[i][1] == pairs[j][0] {
+					pairs[i][1] = pairs[j][1]
+					pairs = append(pairs[:j], pairs[j+1:]...)
+					merged = true
+					break
+				}
+			}
+			if merged {
+				break
+			}
+		}
+	}
+	return normalizeSubMoves(encodePairs(pairs))
+}
+
+// encodePairs packs from/to pairs back into the [8]int move encoding
+func encodePairs(pairs [][2]int) [8]int {
+	move := [8]int{-1, -1, -1, -1, -1, -1, -1, -1}
+	for i, p := range pairs {
+		if i >= 4 {
+			break
+		}
+		move[2*i], move[2*i+1] = p[0], p[1]
+	}
+	return move
+}
+
+// equalSubMoves reports whether two normalised move lists are identical
+func equalSubMoves(a, b [][2]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // parseEncodedMove parses gnuBG's encoded move format
 // Format: sequences of 2 letters representing from/to points
 // a-x represent points 1-24, y is bar (25), z is off (26)
@@ -428,13 +678,13 @@ func processSetBoard(node *SGFNode, game *Game, comment string) error {
 		}
 	}
 
-	// AB sets black checkers
+	// AB sets black checkers, numbered from the other side like its moves
 	if ab := node.Properties["AB"]; len(ab) > 0 {
 		for _, point := range ab {
 			if len(point) == 1 {
 				pt := decodePoint(point[0])
 				if pt >= 0 && pt < 25 {
-					pos.Board[1][pt]++
+					pos.Board[1][sgfPoint(pt, 1)]++
 				}
 			}
 		}
@@ -509,15 +759,20 @@ func parseMoveAnalysis(node *SGFNode, mr *MoveRecord) {
 		//   4 = OUTPUT_LOSEBACKGAMMON (opponent wins backgammon)
 		// And rScore = equity
 
-		opt.Player1WinRate, _ = parseFloat32(parts[4])        // OUTPUT_WIN
-		opt.Player1GammonRate, _ = parseFloat32(parts[5])     // OUTPUT_WINGAMMON
-		opt.Player1BackgammonRate, _ = parseFloat32(parts[6]) // OUTPUT_WINBACKGAMMON
-		opt.Player2GammonRate, _ = parseFloat32(parts[7])     // OUTPUT_LOSEGAMMON
-		opt.Player2BackgammonRate, _ = parseFloat32(parts[8]) // OUTPUT_LOSEBACKGAMMON
-		opt.Equity, _ = strconv.ParseFloat(parts[9], 64)      // rScore (equity)
-
-		// Player2 win rate is calculated as 1.0 - Player1 win rate
-		opt.Player2WinRate = 1.0 - opt.Player1WinRate
+		opt.OnRollWin, _ = parseFloat32(parts[4])          // OUTPUT_WIN
+		opt.OnRollGammon, _ = parseFloat32(parts[5])       // OUTPUT_WINGAMMON
+		opt.OnRollBackgammon, _ = parseFloat32(parts[6])   // OUTPUT_WINBACKGAMMON
+		opt.OpponentGammon, _ = parseFloat32(parts[7])     // OUTPUT_LOSEGAMMON
+		opt.OpponentBackgammon, _ = parseFloat32(parts[8]) // OUTPUT_LOSEBACKGAMMON
+		opt.Equity, _ = strconv.ParseFloat(parts[9], 64)   // rScore (equity)
+
+		// Opponent win rate is calculated as 1.0 - on-roll win rate
+		opt.OpponentWin = 1.0 - opt.OnRollWin
+		setPlayerRates(mr.Player,
+			[3]*float32{&opt.Player1WinRate, &opt.Player1GammonRate, &opt.Player1BackgammonRate},
+			[3]*float32{&opt.Player2WinRate, &opt.Player2GammonRate, &opt.Player2BackgammonRate},
+			[3]float32{opt.OnRollWin, opt.OnRollGammon, opt.OnRollBackgammon},
+			[3]float32{opt.OpponentWin, opt.OpponentGammon, opt.OpponentBackgammon})
 
 		// Set the ply depth from the first element
 		opt.AnalysisDepth = plyDepth
@@ -606,12 +861,23 @@ func parseCubeAnalysis(node *SGFNode, mr *MoveRecord) {
 	pLoseGammon, _ := parseFloat32(parts[10])
 	pLoseBG, _ := parseFloat32(parts[11])
 
-	ca.Player1WinRate = pWin
-	ca.Player1GammonRate = pWinGammon
-	ca.Player1BackgammonRate = pWinBG
-	ca.Player2WinRate = 1.0 - pWin // Opponent win rate = 1 - player win rate
-	ca.Player2GammonRate = pLoseGammon
-	ca.Player2BackgammonRate = pLoseBG
+	ca.OnRollWin = pWin
+	ca.OnRollGammon = pWinGammon
+	ca.OnRollBackgammon = pWinBG
+	ca.OpponentWin = 1.0 - pWin // Opponent win rate = 1 - player win rate
+	ca.OpponentGammon = pLoseGammon
+	ca.OpponentBackgammon = pLoseBG
+
+	// Take and drop records repeat the doubler's analysis
+	onRoll := mr.Player
+	if mr.Type == MoveTypeTake || mr.Type == MoveTypeDrop {
+		onRoll = 1 - mr.Player
+	}
+	setPlayerRates(onRoll,
+		[3]*float32{&ca.Player1WinRate, &ca.Player1GammonRate, &ca.Player1BackgammonRate},
+		[3]*float32{&ca.Player2WinRate, &ca.Player2GammonRate, &ca.Player2BackgammonRate},
+		[3]float32{ca.OnRollWin, ca.OnRollGammon, ca.OnRollBackgammon},
+		[3]float32{ca.OpponentWin, ca.OpponentGammon, ca.OpponentBackgammon})
 
 	// Cubeless equity at index 12
 	ca.CubelessEquity, _ = strconv.ParseFloat(parts[12], 64)
@@ -626,9 +892,9 @@ func parseCubeAnalysis(node *SGFNode, mr *MoveRecord) {
 		ca.CubefulDoubleTake, _ = strconv.ParseFloat(parts[20], 64)
 	}
 
-	// Double/Pass equity: +1.0 for money games (normalized per cube value).
-	// For match play, this should ideally be computed from the match equity table,
-	// but +1.0 is the standard GNUbg convention for the DA property.
+	// Double/Pass equity: the doubler wins the cube value, +1.0 EMG
+	// normalized per cube value in money and match play alike. It is not
+	// an MWC like the other two in match play.
 	ca.CubefulDoublePass = 1.0
 
 	// Set analysis depth from parts[2] if it's numeric
@@ -642,8 +908,21 @@ func parseCubeAnalysis(node *SGFNode, mr *MoveRecord) {
 	mr.CubeAnalysis = ca
 }
 
+// setPlayerRates fills the absolute Player1 and Player2 win, gammon and
+// backgammon fields of a move option or cube analysis from the rates of
+// the player on roll (own) and of the opponent (opp)
+func setPlayerRates(onRoll int, player1, player2 [3]*float32, own, opp [3]float32) {
+	if onRoll == 1 {
+		player1, player2 = player2, player1
+	}
+	for i := range own {
+		*player1[i], *player2[i] = own[i], opp[i]
+	}
+}
+
 // parseLuck parses luck rating (LU property)
-// Format: LU[rating value]
+// Format: LU[value] or LU[rating value]. gnuBG writes LU[-inf] for rolls
+// whose luck was not computed; those are left without a rating.
 func parseLuck(node *SGFNode, mr *MoveRecord) {
 	luStr := getProperty(node, "LU")
 	if luStr == "" {
@@ -651,14 +930,23 @@ func parseLuck(node *SGFNode, mr *MoveRecord) {
 	}
 
 	parts := strings.Fields(luStr)
-	if len(parts) < 2 {
+	if len(parts) == 0 {
 		return
 	}
 
-	mr.Luck = &LuckRating{
-		Rating: parts[0],
+	luck := &LuckRating{}
+	valueStr := parts[0]
+	if len(parts) >= 2 {
+		luck.Rating = parts[0]
+		valueStr = parts[1]
+	}
+
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil || math.IsInf(value, 0) || math.IsNaN(value) {
+		return
 	}
-	mr.Luck.Value, _ = strconv.ParseFloat(parts[1], 64)
+	luck.Value = value
+	mr.Luck = luck
 }
 
 // parseSkill parses skill rating (SK property)
@@ -0,0 +1,62 @@
+package gnubgparser
+
+import "fmt"
+
+// deriveCrawford sets Crawford, CrawfordGame and PostCrawford on every game
+// of a match from the running score. The Crawford game is the first game in
+// which a player needs a single point; later games with a player needing a
+// single point are post-Crawford. The Crawford rule applies to the whole
+// match when any game enables it.
+//
+// When checkFlags is set, the CrawfordGame flags read from the file (SGF
+// RU property) are compared with the derived ones and each disagreement is
+// returned as a warning.
+func deriveCrawford(match *Match, checkFlags bool) []string {
+	matchLength := match.Metadata.MatchLength
+	var warnings []string
+
+	rule := false
+	for _, game := range match.Games {
+		if game.Crawford || game.CrawfordGame {
+			rule = true
+		}
+	}
+
+	played := false
+	for i := range match.Games {
+		game := &match.Games[i]
+		flagged := game.CrawfordGame
+
+		game.Crawford = rule && matchLength > 0
+		game.CrawfordGame = false
+		game.PostCrawford = false
+
+		if game.Crawford {
+			away := [2]int{matchLength - game.Score[0], matchLength - game.Score[1]}
+			switch {
+			case matchLength == 1:
+				// A 1-point match has neither Crawford nor post-Crawford games
+			case away[0] == 1 && away[1] == 1:
+				// Double match point: the Crawford game was played before
+				game.PostCrawford = true
+				played = true
+			case away[0] == 1 || away[1] == 1:
+				game.CrawfordGame = !played
+				game.PostCrawford = played
+				played = true
+			}
+		}
+
+		if checkFlags && flagged != game.CrawfordGame {
+			if flagged {
+				warnings = append(warnings, fmt.Sprintf("game %d: marked as the Crawford game but the score %d-%d is not the first at match point",
+					game.GameNumber, game.Score[0], game.Score[1]))
+			} else {
+				warnings = append(warnings, fmt.Sprintf("game %d: score %d-%d makes it the Crawford game but it is not marked as such",
+					game.GameNumber, game.Score[0], game.Score[1]))
+			}
+		}
+	}
+
+	return warnings
+}
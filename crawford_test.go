@@ -0,0 +1,123 @@
+package gnubgparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeriveCrawford(t *testing.T) {
+	tests := []struct {
+		name         string
+		length       int
+		scores       [][2]int
+		rule         bool
+		wantCrawford []bool
+		wantPost     []bool
+	}{
+		{
+			name:         "Crawford then post-Crawford",
+			length:       5,
+			scores:       [][2]int{{0, 0}, {4, 1}, {4, 2}, {4, 4}},
+			rule:         true,
+			wantCrawford: []bool{false, true, false, false},
+			wantPost:     []bool{false, false, true, true},
+		},
+		{
+			name:         "no Crawford rule",
+			length:       5,
+			scores:       [][2]int{{0, 0}, {4, 1}},
+			wantCrawford: []bool{false, false},
+			wantPost:     []bool{false, false},
+		},
+		{
+			name:         "1-point match",
+			length:       1,
+			scores:       [][2]int{{0, 0}},
+			rule:         true,
+			wantCrawford: []bool{false},
+			wantPost:     []bool{false},
+		},
+		{
+			name:         "money game",
+			length:       0,
+			scores:       [][2]int{{0, 0}, {4, 1}},
+			rule:         true,
+			wantCrawford: []bool{false, false},
+			wantPost:     []bool{false, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := &Match{Metadata: MatchMetadata{MatchLength: tt.length}}
+			for i, score := range tt.scores {
+				match.Games = append(match.Games, Game{GameNumber: i + 1, Score: score, Crawford: tt.rule})
+			}
+			deriveCrawford(match, false)
+			for i, game := range match.Games {
+				if game.CrawfordGame != tt.wantCrawford[i] || game.PostCrawford != tt.wantPost[i] {
+					t.Errorf("Game %d: CrawfordGame = %v, PostCrawford = %v, want %v, %v",
+						i+1, game.CrawfordGame, game.PostCrawford, tt.wantCrawford[i], tt.wantPost[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSGFCrawfordFlags(t *testing.T) {
+	// Game 2 is the Crawford game but RU marks game 3 instead
+	sgf := `(;FF[4]GM[6]MI[length:3][game:0][ws:0][bs:0]RU[Crawford]RE[W+2]
+;W[31fehe])
+(;FF[4]GM[6]MI[length:3][game:1][ws:2][bs:0]RU[Crawford]RE[B+1]
+;W[31fehe])
+(;FF[4]GM[6]MI[length:3][game:2][ws:2][bs:1]RU[Crawford:CrawfordGame]RE[W+1]
+;W[31fehe])
+`
+	match, err := ParseSGF(strings.NewReader(sgf))
+	if err != nil {
+		t.Fatalf("ParseSGF failed: %v", err)
+	}
+
+	want := []struct{ crawford, post bool }{{false, false}, {true, false}, {false, true}}
+	for i, game := range match.Games {
+		if game.CrawfordGame != want[i].crawford || game.PostCrawford != want[i].post {
+			t.Errorf("Game %d: CrawfordGame = %v, PostCrawford = %v, want %v, %v",
+				i+1, game.CrawfordGame, game.PostCrawford, want[i].crawford, want[i].post)
+		}
+	}
+	if len(match.Warnings) != 2 {
+		t.Errorf("Warnings = %v, want one per mismarked game", match.Warnings)
+	}
+}
+
+func TestMATPostCrawford(t *testing.T) {
+	matContent := ` 3 point match
+
+ Game 1
+ alice : 0                   bob : 0
+  1) 31: 8/5 6/5
+      Wins 2 points
+
+ Game 2
+ alice : 2                   bob : 0
+  1) 31: 8/5 6/5
+                                  Wins 1 point
+
+ Game 3
+ alice : 2                   bob : 1
+  1) 31: 8/5 6/5
+      Wins 1 point
+`
+	match, err := ParseMAT(strings.NewReader(matContent))
+	if err != nil {
+		t.Fatalf("ParseMAT failed: %v", err)
+	}
+	if len(match.Games) != 3 {
+		t.Fatalf("Games = %d, want 3", len(match.Games))
+	}
+	if !match.Games[1].CrawfordGame || match.Games[2].CrawfordGame || !match.Games[2].PostCrawford {
+		t.Errorf("Crawford flags = %v/%v, %v/%v",
+			match.Games[1].CrawfordGame, match.Games[1].PostCrawford,
+			match.Games[2].CrawfordGame, match.Games[2].PostCrawford)
+	}
+}
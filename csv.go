@@ -0,0 +1,218 @@
+package gnubgparser
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+)
+
+// CSVColumns is the header written by WriteCSV. Columns are only ever
+// appended, so existing column positions stay stable.
+//
+//	match           1-based index of the match in the WriteCSV call
+//	game            1-based index of the game within the match
+//	move            1-based index of the record within the game
+//	player1/2       player names; match_length is 0 for money play
+//	score1/2        score before the game
+//	crawford_game   true in the Crawford game
+//	player          player making the decision (0 = player1, 1 = player2)
+//	type            move record type (move, double, take, drop, ...)
+//	dice1/2         dice rolled (checker plays)
+//	move_notation   move played
+//	cube_value      cube value before the record
+//	cube_owner      cube owner before the record (-1 = centered)
+//	best_move       best move, or gnuBG's best cube action
+//	played_equity   equity of the played move or cube action
+//	best_equity     equity of the best move or cube action
+//	error           equity lost by the checker play or cube action
+//	                (checker plays keep gnuBG's native equities as read
+//	                from the file; cube actions are EMG, converted from
+//	                MWC with the match equity table in match play)
+//	skill           skill rating of the checker play or cube action
+//	cube_error      equity lost by not doubling before a checker play
+//	cube_skill      skill rating of that no double decision
+//	luck, luck_value luck rating and value of the roll
+//	win, gammon, backgammon, opp_win, opp_gammon, opp_backgammon
+//	                probabilities of the played move, or of the cube
+//	                position, from the point of view of the player on
+//	                roll (the doubler for take and drop)
+//
+// Fields without a value are left empty.
+var CSVColumns = []string{
+	"match", "game", "move", "player1", "player2", "match_length",
+	"score1", "score2", "crawford_game", "player", "type", "dice1", "dice2",
+	"move_notation", "cube_value", "cube_owner", "best_move",
+	"played_equity", "best_equity", "error", "skill", "cube_error", "cube_skill",
+	"luck", "luck_value", "win", "gammon", "backgammon",
+	"opp_win", "opp_gammon", "opp_backgammon",
+}
+
+// csvColumn maps each CSVColumns name to its index
+var csvColumn = func() map[string]int {
+	col := make(map[string]int, len(CSVColumns))
+	for i, name := range CSVColumns {
+		col[name] = i
+	}
+	return col
+}()
+
+// csvRecord is a CSV row in CSVColumns order
+type csvRecord []string
+
+// set fills the named column
+func (r csvRecord) set(column, value string) {
+	r[csvColumn[column]] = value
+}
+
+// WriteCSV writes one row per move record of the matches, with the
+// CSVColumns header
+func WriteCSV(w io.Writer, matches ...*Match) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(CSVColumns); err != nil {
+		return err
+	}
+
+	for m, match := range matches {
+		for g := range match.Games {
+			game := &match.Games[g]
+			cubes, _, _ := replayCube(game)
+			for i := range game.Moves {
+				row := csvRow(match, game, i, cubes[i])
+				row.set("match", strconv.Itoa(m+1))
+				row.set("game", strconv.Itoa(g+1))
+				if err := cw.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvRow builds the CSVColumns fields of a move record, leaving the
+// match and game indexes to the caller
+func csvRow(match *Match, game *Game, index int, cube CubeState) csvRecord {
+	mr := &game.Moves[index]
+	matchLength := match.Metadata.MatchLength
+	row := make(csvRecord, len(CSVColumns))
+
+	row.set("move", strconv.Itoa(index+1))
+	row.set("player1", match.Metadata.Player1)
+	row.set("player2", match.Metadata.Player2)
+	row.set("match_length", strconv.Itoa(matchLength))
+	row.set("score1", strconv.Itoa(game.Score[0]))
+	row.set("score2", strconv.Itoa(game.Score[1]))
+	row.set("crawford_game", strconv.FormatBool(game.CrawfordGame))
+	row.set("player", strconv.Itoa(mr.Player))
+	row.set("type", string(mr.Type))
+	if mr.Dice[0] > 0 {
+		row.set("dice1", strconv.Itoa(mr.Dice[0]))
+		row.set("dice2", strconv.Itoa(mr.Dice[1]))
+	}
+	row.set("move_notation", mr.MoveString)
+	row.set("cube_value", strconv.Itoa(cube.Value))
+	row.set("cube_owner", strconv.Itoa(cube.Owner))
+
+	if mr.Type == MoveTypeNormal && mr.Analysis != nil && len(mr.Analysis.Moves) > 0 {
+		a := mr.Analysis
+		played := a.Moves[0]
+		row.set("best_move", a.Moves[0].MoveString)
+		row.set("best_equity", csvFloat(a.Moves[0].Equity))
+		if a.PlayedKnown() {
+			played = a.Moves[a.SelectedMove]
+			row.set("played_equity", csvFloat(played.Equity))
+			row.set("error", csvFloat(a.EquityLoss))
+		}
+		row.setProbabilities(played.OnRollWin, played.OnRollGammon, played.OnRollBackgammon,
+			played.OpponentWin, played.OpponentGammon, played.OpponentBackgammon)
+	}
+
+	if ca := mr.CubeAnalysis; ca != nil {
+		switch mr.Type {
+		case MoveTypeNormal:
+			cubeError := 0.0
+			if mr.CubeSkill != nil {
+				cubeError = mr.CubeSkill.Error
+			}
+			row.set("cube_error", csvFloat(cubeError))
+			if mr.Analysis == nil {
+				row.setProbabilities(ca.OnRollWin, ca.OnRollGammon, ca.OnRollBackgammon,
+					ca.OpponentWin, ca.OpponentGammon, ca.OpponentBackgammon)
+			}
+		case MoveTypeDouble, MoveTypeTake, MoveTypeDrop:
+			doubler := mr.Player
+			if mr.Type != MoveTypeDouble {
+				doubler = 1 - mr.Player
+			}
+			if nd, dt, dp, ok := cubeEquities(mr, game, matchLength, doubler, cube.Value, match.MET); ok {
+				played, best := csvCubeEquities(mr.Type, nd, dt, dp)
+				row.set("played_equity", csvFloat(played))
+				row.set("best_equity", csvFloat(best))
+				row.set("error", csvFloat(math.Abs(played-best)))
+				row.set("best_move", bestCubeAction(mr.Type, nd, dt, dp))
+			}
+			if ca.BestAction != "" {
+				row.set("best_move", ca.BestAction)
+			}
+			row.setProbabilities(ca.OnRollWin, ca.OnRollGammon, ca.OnRollBackgammon,
+				ca.OpponentWin, ca.OpponentGammon, ca.OpponentBackgammon)
+		}
+	}
+
+	if mr.Skill != nil {
+		row.set("skill", mr.Skill.Rating)
+	}
+	if mr.CubeSkill != nil {
+		row.set("cube_skill", mr.CubeSkill.Rating)
+	}
+	if mr.Luck != nil {
+		row.set("luck", mr.Luck.Rating)
+		row.set("luck_value", csvFloat(mr.Luck.Value))
+	}
+
+	return row
+}
+
+// csvCubeEquities returns the doubler's equity of the action taken and of
+// the best action at a double, take or drop record
+func csvCubeEquities(t MoveType, nd, dt, dp float64) (float64, float64) {
+	response := math.Min(dt, dp)
+	switch t {
+	case MoveTypeTake:
+		return dt, response
+	case MoveTypeDrop:
+		return dp, response
+	}
+	return response, math.Max(nd, response)
+}
+
+// bestCubeAction names the best action at a double, take or drop record
+// with the CubeAnalysis.BestAction values
+func bestCubeAction(t MoveType, nd, dt, dp float64) string {
+	proper := properCubeAction(nd, dt, dp)
+	switch {
+	case t == MoveTypeDouble && !proper.double:
+		return "no_double"
+	case t == MoveTypeDouble:
+		return "double"
+	case !proper.take:
+		return "pass"
+	}
+	return "take"
+}
+
+// setProbabilities fills the probability columns, from the player on
+// roll's win to the opponent's backgammon
+func (r csvRecord) setProbabilities(probs ...float32) {
+	for i, p := range probs {
+		r[csvColumn["win"]+i] = csvFloat(float64(p))
+	}
+}
+
+// csvFloat formats an equity or probability for CSV output
+func csvFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 6, 64)
+}
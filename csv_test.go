@@ -0,0 +1,105 @@
+package gnubgparser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	match, err := ParseSGFFile("test/charlot1-charlot2_7p_2025-11-08-2305.sgf")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, match); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+
+	if strings.Join(rows[0], ",") != strings.Join(CSVColumns, ",") {
+		t.Errorf("Header = %v", rows[0])
+	}
+	records := 0
+	for _, game := range match.Games {
+		records += len(game.Moves)
+	}
+	if len(rows)-1 != records {
+		t.Errorf("Rows = %d, want %d", len(rows)-1, records)
+	}
+
+	col := make(map[string]int)
+	for i, name := range CSVColumns {
+		col[name] = i
+	}
+	first := rows[1]
+	// gnuBG numbers SGF games from 0; the CSV numbers them from 1
+	if first[col["match"]] != "1" || first[col["game"]] != "1" {
+		t.Errorf("First row match/game = %s/%s, want 1/1", first[col["match"]], first[col["game"]])
+	}
+	if first[col["type"]] != "move" || first[col["player1"]] != "charlot1" || first[col["dice1"]] == "" {
+		t.Errorf("First row = %v", first)
+	}
+	if first[col["best_move"]] == "" || first[col["win"]] == "" || first[col["error"]] == "" {
+		t.Errorf("First row has no analysis: %v", first)
+	}
+}
+
+func TestCSVCubeRecords(t *testing.T) {
+	ca := &CubeAnalysis{OnRollWin: 0.8, CubefulNoDouble: 0.6, CubefulDoubleTake: 1.2, CubefulDoublePass: 1}
+	match := &Match{Games: []Game{{Moves: []MoveRecord{
+		{Type: MoveTypeDouble, Player: 0, CubeAnalysis: ca},
+		{Type: MoveTypeTake, Player: 1, CubeAnalysis: ca,
+			Skill: &SkillRating{Rating: "VeryBad", Error: 0.2}},
+	}}}}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, match); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	rows, _ := csv.NewReader(&buf).ReadAll()
+	if len(rows) != 3 {
+		t.Fatalf("Rows = %d, want 3", len(rows))
+	}
+
+	tests := []struct {
+		row                          []string
+		best, played, bestEq, errStr string
+	}{
+		{rows[1], "double", "1.000000", "1.000000", "0.000000"},
+		{rows[2], "pass", "1.200000", "1.000000", "0.200000"},
+	}
+	for _, tt := range tests {
+		if tt.row[16] != tt.best || tt.row[17] != tt.played || tt.row[18] != tt.bestEq || tt.row[19] != tt.errStr {
+			t.Errorf("%s row = %v", tt.row[10], tt.row[16:20])
+		}
+	}
+	if rows[2][20] != "VeryBad" || rows[2][25] != "0.800000" || rows[2][14] != "1" {
+		t.Errorf("Take row = %v", rows[2])
+	}
+}
+
+func TestCSVUnlistedMove(t *testing.T) {
+	// The played move is not among the analysed options: its equity and
+	// error are unknown, not zero
+	match := &Match{Games: []Game{{Moves: []MoveRecord{
+		{Type: MoveTypeNormal, Player: 0, Dice: [2]int{3, 1}, Analysis: &MoveAnalysis{
+			Moves:        []MoveOption{{MoveString: "8/5 6/5", Equity: 0.15}},
+			SelectedMove: SelectedMoveUnknown,
+		}},
+	}}}}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, match); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	rows, _ := csv.NewReader(&buf).ReadAll()
+	if got := rows[1][16:20]; got[0] != "8/5 6/5" || got[1] != "" || got[2] != "0.150000" || got[3] != "" {
+		t.Errorf("Analysis columns = %q", got)
+	}
+}
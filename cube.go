@@ -0,0 +1,157 @@
+package gnubgparser
+
+import (
+	"fmt"
+	"math"
+)
+
+// CubeState is the cube value and owner at a point of a game
+type CubeState struct {
+	Value int `json:"value"`
+	Owner int `json:"owner"` // -1=center, 0=player1, 1=player2
+}
+
+// replayCube replays the cube actions of a game. It returns the cube state
+// in effect before each move record, the state at the end of the game, and
+// whether the last double was dropped. A dropped double does not change
+// the cube. Automatic doubles set the starting value of a centered cube.
+func replayCube(game *Game) ([]CubeState, CubeState, bool) {
+	states := make([]CubeState, len(game.Moves))
+	cube := CubeState{Value: 1 << game.AutoDoubles, Owner: -1}
+	pending := 0
+	beaver := -1 // Player keeping the cube after a beaver
+	dropped := false
+
+	for i, mr := range game.Moves {
+		states[i] = cube
+
+		switch mr.Type {
+		case MoveTypeSetCube:
+			if mr.CubeValue > 0 {
+				cube.Value = mr.CubeValue
+			}
+		case MoveTypeSetCubePos:
+			cube.Owner = mr.CubeOwner
+		case MoveTypeDouble:
+			pending = mr.CubeValue
+			if pending == 0 {
+				pending = cube.Value * 2
+			}
+			if mr.Comment == "Beaver" {
+				beaver = mr.Player
+			}
+			dropped = false
+		case MoveTypeTake:
+			// The taker owns the doubled cube, except after a beaver
+			if pending == 0 {
+				pending = cube.Value * 2
+			}
+			cube = CubeState{Value: pending, Owner: mr.Player}
+			if beaver >= 0 {
+				cube.Owner = beaver
+			}
+			pending, beaver = 0, -1
+		case MoveTypeDrop:
+			dropped = true
+			pending, beaver = 0, -1
+		}
+	}
+
+	return states, cube, dropped
+}
+
+// trackCube stores the cube state in effect before each move record of a
+// game. It returns a description of each double that does not match the
+// cube state: a double by the player who does not own the cube, or to a
+// value other than twice the current one.
+func trackCube(game *Game) []string {
+	states, _, _ := replayCube(game)
+	var warnings []string
+
+	for i := range game.Moves {
+		mr := &game.Moves[i]
+		mr.Cube = states[i]
+		if mr.Type != MoveTypeDouble {
+			continue
+		}
+		if mr.Cube.Owner == 1-mr.Player {
+			warnings = append(warnings, fmt.Sprintf("move %d: player %d doubled a cube owned by the opponent", i+1, mr.Player+1))
+		}
+		// A beaver redoubles the double it accepts
+		want := 2 * mr.Cube.Value
+		if mr.Comment == "Beaver" {
+			want *= 2
+		}
+		if mr.CubeValue > 0 && mr.CubeValue != want {
+			warnings = append(warnings, fmt.Sprintf("move %d: double to %d with the cube at %d", i+1, mr.CubeValue, mr.Cube.Value))
+		}
+	}
+	return warnings
+}
+
+// finalCubeValue returns the cube value at the end of a game, and whether
+// the last double was dropped
+func finalCubeValue(game *Game) (int, bool) {
+	_, final, dropped := replayCube(game)
+	return final.Value, dropped
+}
+
+// autoDoublesFromCubeValue converts the cube value of an SGF root CV
+// property into a number of automatic doubles
+func autoDoublesFromCubeValue(value int) int {
+	n := 0
+	for value > 1 {
+		value /= 2
+		n++
+	}
+	return n
+}
+
+// cubeDecision is the proper cube action at a cube decision, given the no
+// double, double/take and double/pass equities of the doubler
+type cubeDecision struct {
+	double  bool    // The doubler should double
+	take    bool    // The opponent should take a double
+	tooGood bool    // The doubler should play on rather than cash the game
+	equity  float64 // Doubler's equity with the proper actions
+}
+
+// properCubeAction finds the proper cube action the way gnuBG does: the
+// opponent takes when the take costs the doubler less than the pass, and
+// the doubler doubles when that beats not doubling; a position where not
+// doubling beats even a pass is too good to double.
+func properCubeAction(nd, dt, dp float64) cubeDecision {
+	d := cubeDecision{take: dt < dp}
+	response := math.Min(dt, dp)
+	d.double = response >= nd
+	d.tooGood = !d.double && nd > dp
+	d.equity = math.Max(nd, response)
+	return d
+}
+
+// option returns the index of the proper action among no double,
+// double/take and double/pass
+func (d cubeDecision) option() int {
+	switch {
+	case !d.double:
+		return 0
+	case d.take:
+		return 1
+	}
+	return 2
+}
+
+// String names the proper cube action as gnuBG does
+func (d cubeDecision) String() string {
+	response := "pass"
+	if d.take {
+		response = "take"
+	}
+	switch {
+	case d.tooGood:
+		return "Too good to double, " + response
+	case !d.double:
+		return "No double, " + response
+	}
+	return "Double, " + response
+}
@@ -0,0 +1,111 @@
+package gnubgparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCubeTracking(t *testing.T) {
+	sgf := `(;FF[4]GM[6]CV[2]RE[W+8]
+;W[31fehe]
+;B[double]
+;W[take]
+;W[41lpab]
+;CV[8]
+;CP[b]
+;W[double]
+;B[take]
+;B[double]
+;W[take])
+`
+	match, err := ParseSGF(strings.NewReader(sgf))
+	if err != nil {
+		t.Fatalf("ParseSGF failed: %v", err)
+	}
+	game := match.Games[0]
+	if game.AutoDoubles != 1 {
+		t.Errorf("AutoDoubles = %d, want 1", game.AutoDoubles)
+	}
+
+	want := []CubeState{
+		{2, -1}, // Automatic double, repeated by the root CV record
+		{2, -1},
+		{2, -1},
+		{2, -1},
+		{4, 0}, // W took
+		{4, 0},
+		{8, 0}, // CV setup node
+		{8, 1}, // CP setup node
+		{8, 1}, // W doubles a cube owned by B
+		{16, 1},
+		{16, 1},
+	}
+	if len(game.Moves) != len(want) {
+		t.Fatalf("Moves = %d, want %d", len(game.Moves), len(want))
+	}
+	for i, mr := range game.Moves {
+		if mr.Cube != want[i] {
+			t.Errorf("Move %d (%s): cube = %+v, want %+v", i+1, mr.Type, mr.Cube, want[i])
+		}
+	}
+
+	// The double by the player without the cube is reported, and the
+	// final cube of 32 does not match 8 points
+	if len(match.Warnings) != 2 ||
+		!strings.Contains(match.Warnings[0], "owned by the opponent") ||
+		!strings.Contains(match.Warnings[1], "does not match cube 32") {
+		t.Errorf("Warnings = %v", match.Warnings)
+	}
+}
+
+func TestMATCubeTracking(t *testing.T) {
+	matContent := ` 7 point match
+
+ Game 1
+ alice : 0                   bob : 0
+  1)                             41: 13/9 24/23
+  2) 31: 8/5 6/5                 Doubles => 2
+  3)  Takes                      62: 24/18 13/11
+  4)  Doubles => 8               
+  5)                             Drops
+      Wins 2 points
+`
+	match, err := ParseMAT(strings.NewReader(matContent))
+	if err != nil {
+		t.Fatalf("ParseMAT failed: %v", err)
+	}
+
+	moves := match.Games[0].Moves
+	last := moves[len(moves)-1]
+	if last.Type != MoveTypeDrop || last.Cube != (CubeState{2, 0}) {
+		t.Errorf("Drop = %s with cube %+v, want drop with cube 2 owned by player 0", last.Type, last.Cube)
+	}
+	if len(match.Warnings) != 1 || !strings.Contains(match.Warnings[0], "double to 8 with the cube at 2") {
+		t.Errorf("Warnings = %v", match.Warnings)
+	}
+}
+
+func TestProperCubeAction(t *testing.T) {
+	tests := []struct {
+		nd, dt, dp float64
+		want       string
+		option     int
+		equity     float64
+	}{
+		{0.3, 0.2, 1.0, "No double, take", 0, 0.3},
+		{0.5, 0.8, 1.0, "Double, take", 1, 0.8},
+		{0.7, 1.2, 1.0, "Double, pass", 2, 1.0},
+		{1.1, 1.4, 1.0, "Too good to double, pass", 0, 1.1},
+		{1.1, 0.9, 1.0, "Too good to double, take", 0, 1.1},
+	}
+	for _, tt := range tests {
+		got := properCubeAction(tt.nd, tt.dt, tt.dp)
+		if got.String() != tt.want || got.option() != tt.option || got.equity != tt.equity {
+			t.Errorf("properCubeAction(%v, %v, %v) = %s, option %d, equity %v; want %s, option %d, equity %v",
+				tt.nd, tt.dt, tt.dp, got, got.option(), got.equity, tt.want, tt.option, tt.equity)
+		}
+		if got := bestCubeAction(MoveTypeDouble, tt.nd, tt.dt, tt.dp); (got == "no_double") != (tt.option == 0) {
+			t.Errorf("bestCubeAction(double, %v, %v, %v) = %s", tt.nd, tt.dt, tt.dp, got)
+		}
+	}
+}
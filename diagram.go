@@ -0,0 +1,392 @@
+package gnubgparser
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// DiagramOptions controls how RenderSVG and RenderPNG draw a position
+type DiagramOptions struct {
+	// Image width in pixels (default 600); the height follows from it
+	Width int
+	// Player drawn at the bottom, whose home board is on the right
+	Bottom int
+	// Number the points from the top player's side instead of the bottom's
+	FlipNumbers bool
+	// Player names; empty names are shown as "Player 1" and "Player 2"
+	Names [2]string
+	// Checkers each player starts with, for borne-off counts (default 15)
+	Checkers int
+	// Parts of the diagram to leave out
+	HideCube, HideDice, HideScore bool
+	// Checker hops of the player on roll to draw as arrows, from that
+	// player's side as in SubMove (see MoveRecord.Hops)
+	Move []SubMove
+	// Text drawn below the board, such as the move played
+	Caption string
+	// Colours; fields left zero use DefaultDiagramColors
+	Colors DiagramColors
+}
+
+// DiagramColors holds the colours of a board diagram
+type DiagramColors struct {
+	Background color.RGBA
+	Frame      color.RGBA // Board edge, bar and bear-off tray border
+	Board      color.RGBA // Playing surface
+	DarkPoint  color.RGBA
+	LightPoint color.RGBA
+	Checkers   [2]color.RGBA // Checkers of Player1 and Player2
+	Edge       color.RGBA    // Checker, cube and dice outlines
+	Text       color.RGBA
+	Arrow      color.RGBA
+}
+
+// DefaultDiagramColors are the colours used for fields left zero
+var DefaultDiagramColors = DiagramColors{
+	Background: color.RGBA{0xff, 0xff, 0xff, 0xff},
+	Frame:      color.RGBA{0x6b, 0x44, 0x23, 0xff},
+	Board:      color.RGBA{0xe9, 0xdc, 0xbc, 0xff},
+	DarkPoint:  color.RGBA{0x9c, 0x4a, 0x2c, 0xff},
+	LightPoint: color.RGBA{0xc8, 0xa8, 0x78, 0xff},
+	Checkers:   [2]color.RGBA{{0xf7, 0xf4, 0xee, 0xff}, {0x2b, 0x2b, 0x2b, 0xff}},
+	Edge:       color.RGBA{0x20, 0x20, 0x20, 0xff},
+	Text:       color.RGBA{0x10, 0x10, 0x10, 0xff},
+	Arrow:      color.RGBA{0x1f, 0x6f, 0xd6, 0xc0},
+}
+
+// withDefaults fills zero colours from DefaultDiagramColors
+func (c DiagramColors) withDefaults() DiagramColors {
+	d := DefaultDiagramColors
+	pick := func(v *color.RGBA, def color.RGBA) {
+		if *v == (color.RGBA{}) {
+			*v = def
+		}
+	}
+	pick(&c.Background, d.Background)
+	pick(&c.Frame, d.Frame)
+	pick(&c.Board, d.Board)
+	pick(&c.DarkPoint, d.DarkPoint)
+	pick(&c.LightPoint, d.LightPoint)
+	pick(&c.Checkers[0], d.Checkers[0])
+	pick(&c.Checkers[1], d.Checkers[1])
+	pick(&c.Edge, d.Edge)
+	pick(&c.Text, d.Text)
+	pick(&c.Arrow, d.Arrow)
+	return c
+}
+
+// shapeKind is the kind of a diagram shape
+type shapeKind int
+
+const (
+	shapeRect    shapeKind = iota // pts[0] and pts[1] are opposite corners
+	shapePolygon                  // pts are the vertices
+	shapeCircle                   // pts[0] is the centre, size the radius
+	shapeLine                     // pts are the ends, size the width
+	shapeText                     // pts[0] is the anchor, size the height
+)
+
+// textAnchor is the horizontal alignment of a text shape
+type textAnchor int
+
+const (
+	anchorStart textAnchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+// point is a diagram coordinate in pixels
+type point struct{ X, Y float64 }
+
+// shape is one element of a diagram, drawn by both the SVG and the raster
+// renderer; a zero stroke colour means no outline
+type shape struct {
+	kind   shapeKind
+	pts    []point
+	size   float64
+	fill   color.RGBA
+	stroke color.RGBA
+	text   string
+	anchor textAnchor
+}
+
+// diagram is a position laid out as shapes
+type diagram struct {
+	width, height float64
+	background    color.RGBA
+	shapes        []shape
+}
+
+// Board layout, in units of a sixteenth of the width: the cube column, the
+// frame, six points, the bar, six points and the bear-off tray
+const (
+	unitsWide    = 16.0
+	cubeColumn   = 1.0
+	frameWidth   = 0.3
+	leftPoints   = cubeColumn + frameWidth
+	barLeft      = leftPoints + 6
+	rightPoints  = barLeft + 1
+	trayLeft     = rightPoints + 6 + frameWidth
+	trayWidth    = 1.0
+	boardHeight  = 11.0
+	numberHeight = 0.6
+	infoHeight   = 0.8
+	checkerSize  = 0.9
+	pointHeight  = 4.6
+)
+
+// layoutDiagram lays out a position for RenderSVG and RenderPNG
+func layoutDiagram(pos *Position, opts DiagramOptions) *diagram {
+	width := opts.Width
+	if width <= 0 {
+		width = 600
+	}
+	u := float64(width) / unitsWide
+	colors := opts.Colors.withDefaults()
+	bottom := opts.Bottom
+	if bottom != 1 {
+		bottom = 0
+	}
+	top := 1 - bottom
+	names := opts.Names
+	for i := range names {
+		if names[i] == "" {
+			names[i] = fmt.Sprintf("Player %d", i+1)
+		}
+	}
+	checkers := opts.Checkers
+	if checkers <= 0 {
+		checkers = 15
+	}
+	info := infoHeight
+	if opts.HideScore {
+		info = 0
+	}
+	caption := 0.0
+	if opts.Caption != "" {
+		caption = infoHeight
+	}
+
+	d := &diagram{
+		width:      float64(width),
+		height:     math.Round((2*info + 2*numberHeight + boardHeight + 2*frameWidth + caption) * u),
+		background: colors.Background,
+	}
+	rect := func(x0, y0, x1, y1 float64, fill, stroke color.RGBA) {
+		d.shapes = append(d.shapes, shape{kind: shapeRect, pts: []point{{x0 * u, y0 * u}, {x1 * u, y1 * u}}, fill: fill, stroke: stroke})
+	}
+	circle := func(x, y, r float64, fill, stroke color.RGBA) {
+		d.shapes = append(d.shapes, shape{kind: shapeCircle, pts: []point{{x * u, y * u}}, size: r * u, fill: fill, stroke: stroke})
+	}
+	text := func(x, y, size float64, s string, c color.RGBA, anchor textAnchor) {
+		d.shapes = append(d.shapes, shape{kind: shapeText, pts: []point{{x * u, y * u}}, size: size * u, fill: c, text: s, anchor: anchor})
+	}
+
+	frameTop := info + numberHeight
+	y0 := frameTop + frameWidth // Inner top edge
+	y1 := y0 + boardHeight      // Inner bottom edge
+	mid := (y0 + y1) / 2
+	rect(cubeColumn, frameTop, unitsWide, y1+frameWidth, colors.Frame, color.RGBA{})
+	rect(leftPoints, y0, barLeft, y1, colors.Board, color.RGBA{})
+	rect(rightPoints, y0, rightPoints+6, y1, colors.Board, color.RGBA{})
+	rect(trayLeft, y0, trayLeft+trayWidth, mid-0.1, colors.Board, color.RGBA{})
+	rect(trayLeft, mid+0.1, trayLeft+trayWidth, y1, colors.Board, color.RGBA{})
+
+	// Points are indexed from the bottom player's side; the top row runs
+	// 13-24 and the bottom row 12-1 from left to right
+	column := func(i int) float64 {
+		switch {
+		case i < 6:
+			return rightPoints + float64(5-i)
+		case i < 12:
+			return leftPoints + float64(11-i)
+		case i < 18:
+			return leftPoints + float64(i-12)
+		}
+		return rightPoints + float64(i-18)
+	}
+	r := checkerSize / 2
+	for i := 0; i < 24; i++ {
+		x := column(i)
+		fill := colors.LightPoint
+		if i%2 == 0 {
+			fill = colors.DarkPoint
+		}
+		base, tip := y1, y1-pointHeight
+		if i >= 12 {
+			base, tip = y0, y0+pointHeight
+		}
+		d.shapes = append(d.shapes, shape{kind: shapePolygon, fill: fill,
+			pts: []point{{x * u, base * u}, {(x + 1) * u, base * u}, {(x + 0.5) * u, tip * u}}})
+
+		label := i + 1
+		if opts.FlipNumbers {
+			label = 24 - i
+		}
+		ny := y1 + frameWidth + numberHeight/2
+		if i >= 12 {
+			ny = frameTop - numberHeight/2
+		}
+		text(x+0.5, ny, 0.4, fmt.Sprint(label), colors.Text, anchorMiddle)
+	}
+
+	// stack draws n checkers from (x, y) in direction dir, at most max of
+	// them with a count on the last one when there are more
+	stack := func(player, n int, x, y, dir float64, max int) {
+		shown := n
+		if shown > max {
+			shown = max
+		}
+		for j := 0; j < shown; j++ {
+			cy := y + dir*(r+float64(j)*checkerSize)
+			circle(x, cy, r*0.95, colors.Checkers[player], colors.Edge)
+			if j == shown-1 && n > shown {
+				text(x, cy, 0.4, fmt.Sprint(n), colors.Checkers[1-player], anchorMiddle)
+			}
+		}
+	}
+	for i := 0; i < 24; i++ {
+		x := column(i) + 0.5
+		edge, dir := y1, -1.0
+		if i >= 12 {
+			edge, dir = y0, 1.0
+		}
+		if n := pos.Board[bottom][i]; n > 0 {
+			stack(bottom, n, x, edge, dir, 5)
+		}
+		if n := pos.Board[top][23-i]; n > 0 {
+			stack(top, n, x, edge, dir, 5)
+		}
+	}
+	stack(bottom, pos.Board[bottom][24], barLeft+0.5, mid+0.6, 1, 4)
+	stack(top, pos.Board[top][24], barLeft+0.5, mid-0.6, -1, 4)
+
+	// Borne-off checkers lie sideways in the tray
+	for _, p := range []int{bottom, top} {
+		for j := 0; j < pos.BorneOff(p, checkers); j++ {
+			yy := y1 - 0.1 - float64(j+1)*0.32
+			if p == top {
+				yy = y0 + 0.1 + float64(j)*0.32
+			}
+			rect(trayLeft+0.1, yy, trayLeft+trayWidth-0.1, yy+0.3, colors.Checkers[p], colors.Edge)
+		}
+	}
+
+	if !opts.HideCube && pos.CubeValue > 0 {
+		cy := mid
+		switch pos.CubeOwner {
+		case bottom:
+			cy = y1 - 0.6
+		case top:
+			cy = y0 + 0.6
+		}
+		value := pos.CubeValue
+		if value == 1 && pos.CubeOwner != 0 && pos.CubeOwner != 1 {
+			value = 64
+		}
+		rect(0.1, cy-0.4, 0.9, cy+0.4, colors.Background, colors.Edge)
+		text(0.5, cy, 0.45, fmt.Sprint(value), colors.Text, anchorMiddle)
+	}
+
+	if !opts.HideDice && pos.Dice[0] > 0 && (pos.OnRoll == 0 || pos.OnRoll == 1) {
+		// The player on roll's dice, on the right half as in gnuBG
+		for k, die := range pos.Dice {
+			cx := rightPoints + 3 + (float64(k)-0.5)*1.2
+			rect(cx-0.4, mid-0.4, cx+0.4, mid+0.4, colors.Checkers[pos.OnRoll], colors.Edge)
+			if die < 1 || die > 6 {
+				continue
+			}
+			for _, pip := range dicePips[die] {
+				circle(cx+pip[0]*0.22, mid+pip[1]*0.22, 0.08, colors.Checkers[1-pos.OnRoll], color.RGBA{})
+			}
+		}
+	}
+
+	if !opts.HideScore {
+		for _, p := range []int{top, bottom} {
+			y := info / 2
+			if p == bottom {
+				y = y1 + frameWidth + numberHeight + info/2
+			}
+			score := fmt.Sprintf("%s: %d", names[p], pos.Score[p])
+			if pos.MatchLength > 0 {
+				score += fmt.Sprintf("/%d", pos.MatchLength)
+			}
+			text(cubeColumn, y, 0.45, score, colors.Text, anchorStart)
+			text(unitsWide, y, 0.45, fmt.Sprintf("Pips: %d", pos.PipCount(p)), colors.Text, anchorEnd)
+		}
+		if pos.Crawford {
+			text(barLeft+0.5, info/2, 0.45, "Crawford", colors.Text, anchorMiddle)
+		}
+	}
+
+	if opts.Caption != "" {
+		text(unitsWide/2, y1+frameWidth+numberHeight+info+caption/2, 0.45, opts.Caption, colors.Text, anchorMiddle)
+	}
+
+	// Arrows for the move, from the player on roll's side
+	mover := pos.OnRoll
+	at := func(pt int) (float64, float64) {
+		side := 1.0 // Towards the mover's edge of the board
+		if mover == top {
+			side = -1
+		}
+		switch {
+		case pt == 24:
+			return barLeft + 0.5, mid + side*1.5
+		case pt < 0:
+			return trayLeft + trayWidth/2, mid + side*2.5
+		}
+		i := pt
+		if mover != bottom {
+			i = 23 - pt
+		}
+		if i >= 12 {
+			return column(i) + 0.5, y0 + 2.2
+		}
+		return column(i) + 0.5, y1 - 2.2
+	}
+	for _, hop := range opts.Move {
+		if mover != 0 && mover != 1 {
+			break
+		}
+		fx, fy := at(hop.From)
+		tx, ty := at(hop.To)
+		d.arrow(point{fx * u, fy * u}, point{tx * u, ty * u}, 0.12*u, colors.Arrow)
+	}
+	return d
+}
+
+// arrow adds a line from a to b with a head at b
+func (d *diagram) arrow(a, b point, width float64, c color.RGBA) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return
+	}
+	ux, uy := dx/length, dy/length
+	head := width * 3.5
+	if head > length/2 {
+		head = length / 2
+	}
+	base := point{b.X - ux*head, b.Y - uy*head}
+	d.shapes = append(d.shapes,
+		shape{kind: shapeLine, pts: []point{a, base}, size: width, fill: c},
+		shape{kind: shapePolygon, fill: c, pts: []point{
+			b,
+			{base.X - uy*head*0.5, base.Y + ux*head*0.5},
+			{base.X + uy*head*0.5, base.Y - ux*head*0.5},
+		}})
+}
+
+// dicePips are the pip offsets of each die face, in units of a quarter of
+// the die
+var dicePips = [7][][2]float64{
+	1: {{0, 0}},
+	2: {{-1, -1}, {1, 1}},
+	3: {{-1, -1}, {0, 0}, {1, 1}},
+	4: {{-1, -1}, {1, -1}, {-1, 1}, {1, 1}},
+	5: {{-1, -1}, {1, -1}, {0, 0}, {-1, 1}, {1, 1}},
+	6: {{-1, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {1, 1}},
+}
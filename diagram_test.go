@@ -0,0 +1,72 @@
+package gnubgparser
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestRenderSVG(t *testing.T) {
+	pos := Position{Board: startingBoard("Standard"), CubeValue: 1, CubeOwner: -1, MatchLength: 7}
+
+	tests := []struct {
+		name    string
+		opts    DiagramOptions
+		want    []string
+		circles int
+	}{
+		{"start", DiagramOptions{Names: [2]string{"Alice", "Bob"}},
+			[]string{`width="600" height="540"`, ">Alice: 0/7</text>", ">Pips: 167</text>", ">64</text>"}, 30},
+		{"no score or cube", DiagramOptions{HideScore: true, HideCube: true},
+			[]string{`height="480"`}, 30},
+		{"arrows", DiagramOptions{Move: []SubMove{{From: 7, To: 4}, {From: 5, To: 4}}},
+			[]string{"<line ", `stroke="#1f6fd6" stroke-opacity="0.75"`}, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := pos.RenderSVG(&buf, tt.opts); err != nil {
+				t.Fatalf("RenderSVG failed: %v", err)
+			}
+			out := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("SVG does not contain %q", want)
+				}
+			}
+			if n := strings.Count(out, "<circle"); n != tt.circles {
+				t.Errorf("SVG has %d circles, want %d", n, tt.circles)
+			}
+		})
+	}
+}
+
+func TestRenderPNG(t *testing.T) {
+	pos := Position{Board: startingBoard("Standard"), CubeValue: 1, CubeOwner: -1, Dice: [2]int{3, 1}}
+
+	for bottom := 0; bottom < 2; bottom++ {
+		var buf bytes.Buffer
+		if err := pos.RenderPNG(&buf, DiagramOptions{Bottom: bottom}); err != nil {
+			t.Fatalf("RenderPNG failed: %v", err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatalf("Decoding PNG failed: %v", err)
+		}
+		if b := img.Bounds(); b.Dx() != 600 || b.Dy() != 540 {
+			t.Fatalf("PNG size = %dx%d, want 600x540", b.Dx(), b.Dy())
+		}
+
+		// The 6-points of both players sit on the right, the bottom
+		// player's at the bottom
+		for _, c := range []struct {
+			x, y, player int
+		}{{330, 459, bottom}, {330, 81, 1 - bottom}} {
+			want := DefaultDiagramColors.Checkers[c.player]
+			if got := img.At(c.x, c.y); got != want {
+				t.Errorf("Bottom %d: pixel (%d,%d) = %v, want player %d's %v", bottom, c.x, c.y, got, c.player, want)
+			}
+		}
+	}
+}
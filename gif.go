@@ -0,0 +1,129 @@
+package gnubgparser
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+)
+
+// GIFOptions controls WriteGameGIF
+type GIFOptions struct {
+	// Size, colours, names and orientation of the frames; Move and Caption
+	// are set for each frame
+	Diagram DiagramOptions
+	// Delay between frames in hundredths of a second (default 150); the
+	// final position is shown three times as long
+	Delay int
+}
+
+// WriteGameGIF replays a game as an animated GIF with one frame per
+// half-move: the position before each checker play, double, take, pass or
+// resignation with the dice, the move drawn as arrows and a caption, then
+// the final position with the result
+func WriteGameGIF(w io.Writer, game *Game, matchLength int, opts GIFOptions) error {
+	delay := opts.Delay
+	if delay <= 0 {
+		delay = 150
+	}
+	names := opts.Diagram.Names
+	for i := range names {
+		if names[i] == "" {
+			names[i] = fmt.Sprintf("Player %d", i+1)
+		}
+	}
+	if opts.Diagram.Checkers <= 0 {
+		opts.Diagram.Checkers = checkersPerSide(game.Variation)
+	}
+	palette := diagramPalette(opts.Diagram.Colors.withDefaults())
+	anim := &gif.GIF{}
+	frame := func(pos *Position, move []SubMove, caption string, delay int) {
+		d := opts.Diagram
+		d.Move = move
+		d.Caption = caption
+		anim.Image = append(anim.Image, palette.convert(pos.RenderImage(d)))
+		anim.Delay = append(anim.Delay, delay)
+	}
+
+	positions := GamePositions(game, matchLength)
+	for i := range game.Moves {
+		mr := &game.Moves[i]
+		pos := positions[i]
+		if mr.Player != 0 && mr.Player != 1 {
+			continue
+		}
+		name := names[mr.Player]
+
+		switch mr.Type {
+		case MoveTypeNormal:
+			after := pos
+			hops := after.applyMove(mr.Player, mr.Hops())
+			frame(&pos, hops, fmt.Sprintf("%s %d%d: %s", name, mr.Dice[0], mr.Dice[1], formatHops(hops)), delay)
+		case MoveTypeDouble:
+			frame(&pos, nil, fmt.Sprintf("%s doubles to %d", name, 2*pos.CubeValue), delay)
+		case MoveTypeTake:
+			frame(&pos, nil, name+" takes", delay)
+		case MoveTypeDrop:
+			frame(&pos, nil, name+" passes", delay)
+		case MoveTypeResign:
+			frame(&pos, nil, name+" resigns", delay)
+		}
+	}
+	final := finalPosition(game, positions, matchLength)
+	frame(&final, nil, gameResultText(game, names), 3*delay)
+
+	return gif.EncodeAll(w, anim)
+}
+
+// framePalette maps the colours of a diagram to a GIF palette, remembering
+// the nearest palette entry of each colour seen
+type framePalette struct {
+	colors color.Palette
+	index  map[color.RGBA]uint8
+}
+
+// diagramPalette builds a palette from the diagram colours and blends of
+// each pair of them, which covers the antialiased edges
+func diagramPalette(c DiagramColors) *framePalette {
+	arrow := c.Arrow
+	arrow.A = 0xff
+	base := []color.RGBA{c.Background, c.Frame, c.Board, c.DarkPoint, c.LightPoint,
+		c.Checkers[0], c.Checkers[1], c.Edge, c.Text, arrow}
+
+	p := &framePalette{index: map[color.RGBA]uint8{}}
+	add := func(col color.RGBA) {
+		if _, ok := p.index[col]; !ok && len(p.colors) < 256 {
+			p.index[col] = uint8(len(p.colors))
+			p.colors = append(p.colors, col)
+		}
+	}
+	for _, col := range base {
+		add(col)
+	}
+	mix := func(a, b uint8, t float64) uint8 { return uint8(float64(a)*(1-t) + float64(b)*t + 0.5) }
+	for i := range base {
+		for j := i + 1; j < len(base); j++ {
+			for _, t := range []float64{1.0 / 3, 2.0 / 3} {
+				a, b := base[i], base[j]
+				add(color.RGBA{mix(a.R, b.R, t), mix(a.G, b.G, t), mix(a.B, b.B, t), 0xff})
+			}
+		}
+	}
+	return p
+}
+
+// convert maps an image to the palette without dithering
+func (p *framePalette) convert(img *image.RGBA) *image.Paletted {
+	out := image.NewPaletted(img.Bounds(), p.colors)
+	for i := 0; i < len(img.Pix); i += 4 {
+		col := color.RGBA{img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]}
+		idx, ok := p.index[col]
+		if !ok {
+			idx = uint8(p.colors.Index(col))
+			p.index[col] = idx
+		}
+		out.Pix[i/4] = idx
+	}
+	return out
+}
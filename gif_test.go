@@ -0,0 +1,40 @@
+package gnubgparser
+
+import (
+	"bytes"
+	"image/gif"
+	"testing"
+)
+
+func TestWriteGameGIF(t *testing.T) {
+	game := &Game{Winner: 0, Points: 1, ResultKind: ResultDroppedDouble, Moves: []MoveRecord{
+		{Type: MoveTypeNormal, Player: 0, Dice: [2]int{3, 1}, SubMoves: []SubMove{{From: 7, To: 4}, {From: 5, To: 4}}},
+		{Type: MoveTypeNormal, Player: 1, Dice: [2]int{6, 5}, SubMoves: []SubMove{{From: 23, To: 17}, {From: 17, To: 12}}},
+		{Type: MoveTypeDouble, Player: 0},
+		{Type: MoveTypeDrop, Player: 1},
+	}}
+
+	var buf bytes.Buffer
+	opts := GIFOptions{Diagram: DiagramOptions{Width: 320}, Delay: 80}
+	if err := WriteGameGIF(&buf, game, 0, opts); err != nil {
+		t.Fatalf("WriteGameGIF failed: %v", err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("Decoding GIF failed: %v", err)
+	}
+
+	// One frame per record and the final position, shown longer
+	wantDelays := []int{80, 80, 80, 80, 240}
+	if len(anim.Image) != len(wantDelays) {
+		t.Fatalf("GIF has %d frames, want %d", len(anim.Image), len(wantDelays))
+	}
+	for i, img := range anim.Image {
+		if anim.Delay[i] != wantDelays[i] {
+			t.Errorf("Frame %d delay = %d, want %d", i, anim.Delay[i], wantDelays[i])
+		}
+		if b := img.Bounds(); b.Dx() != 320 || b.Dy() != anim.Image[0].Bounds().Dy() {
+			t.Errorf("Frame %d size = %dx%d, want the width of 320 for all frames", i, b.Dx(), b.Dy())
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package gnubgparser
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// htmlReport is the data rendered by the HTML report template
+type htmlReport struct {
+	Title string
+	Meta  []htmlField
+	Names [2]string
+	Stats []htmlStatRow
+	Games []htmlGame
+}
+
+type htmlField struct {
+	Label, Value string
+}
+
+type htmlStatRow struct {
+	Label  string
+	Values [2]string
+}
+
+type htmlGame struct {
+	Number int
+	Score  string
+	Result string
+	Moves  []htmlMove
+}
+
+type htmlMove struct {
+	Number   int
+	Player   string
+	Dice     string
+	Action   string
+	Error    string
+	Rating   string
+	Class    string // Severity of the worst error, used as CSS class
+	Luck     string
+	Comment  string
+	Options  []htmlOption
+	Cube     []htmlCubeRow
+	CubeBest string
+}
+
+type htmlOption struct {
+	Rank   int
+	Move   string
+	Equity string
+	Diff   string
+	Probs  [6]string // On-roll win, gammon, backgammon, then the opponent's
+	Played bool
+}
+
+type htmlCubeRow struct {
+	Action string
+	Equity string
+	Best   bool
+}
+
+// WriteHTML writes a single-file HTML analysis report of a match: header,
+// statistics, per-game results and move lists with errors highlighted by
+// severity and the analysis of each decision. The report uses no external
+// assets.
+func WriteHTML(w io.Writer, match *Match) error {
+	return htmlTemplate.Execute(w, newHTMLReport(match))
+}
+
+// newHTMLReport builds the template data of a match
+func newHTMLReport(match *Match) htmlReport {
+	md := match.Metadata
+	names := playerNames(match)
+	report := htmlReport{
+		Title: names[0] + " vs " + names[1],
+		Names: names,
+	}
+
+	length := "Money game"
+	if md.MatchLength > 0 {
+		length = fmt.Sprintf("%d points", md.MatchLength)
+	}
+	report.Meta = append(report.Meta, htmlField{"Match length", length})
+	for _, f := range []htmlField{
+		{"Event", md.Event}, {"Round", md.Round}, {"Place", md.Place},
+		{"Date", md.Date}, {"Annotator", md.Annotator}, {"Application", md.Application},
+	} {
+		if f.Value != "" {
+			report.Meta = append(report.Meta, f)
+		}
+	}
+
+	stats := match.Statistics()
+	score := fmt.Sprintf("%d-%d", stats.FinalScore[0], stats.FinalScore[1])
+	if stats.Winner >= 0 {
+		score += ", " + names[stats.Winner] + " wins"
+	}
+	report.Meta = append(report.Meta, htmlField{"Final score", score})
+	report.Stats = htmlStatistics(match, stats)
+
+	for i := range match.Games {
+		report.Games = append(report.Games, newHTMLGame(i, &match.Games[i], names, md.MatchLength, match.MET))
+	}
+	return report
+}
+
+// playerNames returns the player names, with defaults for missing ones
+func playerNames(match *Match) [2]string {
+	names := [2]string{match.Metadata.Player1, match.Metadata.Player2}
+	for i := range names {
+		if names[i] == "" {
+			names[i] = fmt.Sprintf("Player %d", i+1)
+		}
+	}
+	return names
+}
+
+// htmlStatistics lists the performance and luck figures of both players
+func htmlStatistics(match *Match, stats MatchStatistics) []htmlStatRow {
+	perf := stats.Performance
+	luck := MatchLuck(match)
+	var rows []htmlStatRow
+
+	row := func(label string, value func(p int) string) {
+		r := htmlStatRow{Label: label}
+		for p := 0; p < 2; p++ {
+			r.Values[p] = value(p)
+		}
+		rows = append(rows, r)
+	}
+	pp := func(p int) PlayerPerformance { return perf.Players[p] }
+
+	row("PR", func(p int) string { return fmt.Sprintf("%.2f", pp(p).PR()) })
+	row("Checker PR", func(p int) string { return fmt.Sprintf("%.2f", pp(p).CheckerPR()) })
+	row("Cube PR", func(p int) string { return fmt.Sprintf("%.2f", pp(p).CubePR()) })
+	row("Snowie error rate", func(p int) string { return fmt.Sprintf("%.2f", perf.SnowieErrorRate(p)) })
+	row("Unforced checker plays", func(p int) string { return fmt.Sprint(pp(p).CheckerDecisions) })
+	row("Cube decisions", func(p int) string { return fmt.Sprint(pp(p).CubeDecisions) })
+	row("Checker errors (doubtful/bad/very bad)", func(p int) string {
+		c := pp(p).CheckerCounts
+		return fmt.Sprintf("%d / %d / %d", c.Doubtful, c.Bad, c.VeryBad)
+	})
+	row("Cube errors (doubtful/bad/very bad)", func(p int) string {
+		c := pp(p).CubeCounts
+		return fmt.Sprintf("%d / %d / %d", c.Doubtful, c.Bad, c.VeryBad)
+	})
+	row("Missed doubles / wrong doubles", func(p int) string {
+		return fmt.Sprintf("%d / %d", pp(p).MissedDoubles, pp(p).WrongDoubles)
+	})
+	row("Wrong takes / wrong passes", func(p int) string {
+		return fmt.Sprintf("%d / %d", pp(p).WrongTakes, pp(p).WrongPasses)
+	})
+	row("Luck", func(p int) string { return fmt.Sprintf("%+.3f", luck.Players[p].Total) })
+	row("Jokers / anti-jokers", func(p int) string {
+		return fmt.Sprintf("%d / %d", luck.Players[p].Jokers, luck.Players[p].AntiJokers)
+	})
+	row("Luck-adjusted result", func(p int) string { return fmt.Sprintf("%.3f", luck.LuckAdjusted[p]) })
+	row("Gammons / backgammons", func(p int) string {
+		return fmt.Sprintf("%d / %d", stats.Gammons[p], stats.Backgammons[p])
+	})
+	return rows
+}
+
+// newHTMLGame builds the template data of a game
+func newHTMLGame(index int, game *Game, names [2]string, matchLength int, met *MatchEquityTable) htmlGame {
+	g := htmlGame{
+		Number: index + 1,
+		Score:  fmt.Sprintf("%d-%d", game.Score[0], game.Score[1]),
+		Result: gameResultText(game, names),
+	}
+	if game.CrawfordGame {
+		g.Score += " (Crawford)"
+	}
+
+	cubes, _, _ := replayCube(game)
+	for i := range game.Moves {
+		mr := &game.Moves[i]
+		if mr.Type == MoveTypeSetBoard || mr.Type == MoveTypeSetDice ||
+			mr.Type == MoveTypeSetCube || mr.Type == MoveTypeSetCubePos {
+			continue
+		}
+		g.Moves = append(g.Moves, newHTMLMove(i, mr, game, names, matchLength, cubes[i].Value, met))
+	}
+	return g
+}
+
+// gameResultText describes the result of a game
+func gameResultText(game *Game, names [2]string) string {
+	if game.Winner != 0 && game.Winner != 1 {
+		return "Not finished"
+	}
+	text := fmt.Sprintf("%s wins %d point", names[game.Winner], game.Points)
+	if game.Points != 1 {
+		text += "s"
+	}
+	if game.ResultKind != "" {
+		text += " (" + strings.ReplaceAll(string(game.ResultKind), "_", " ") + ")"
+	}
+	return text
+}
+
+// newHTMLMove builds the template data of a move record
+func newHTMLMove(index int, mr *MoveRecord, game *Game, names [2]string, matchLength, cube int, met *MatchEquityTable) htmlMove {
+	m := htmlMove{Number: index + 1, Comment: mr.Comment}
+	if mr.Player == 0 || mr.Player == 1 {
+		m.Player = names[mr.Player]
+	}
+
+	switch mr.Type {
+	case MoveTypeNormal:
+		m.Dice = fmt.Sprintf("%d%d", mr.Dice[0], mr.Dice[1])
+		m.Action = mr.MoveString
+	case MoveTypeResign:
+		m.Action = "Resigns"
+		if mr.ResignLevel > 0 {
+			m.Action += fmt.Sprintf(" (%d)", mr.ResignLevel)
+		}
+	default:
+		m.Action = strings.ToUpper(string(mr.Type[:1])) + string(mr.Type[1:])
+	}
+
+	var errs, ratings []string
+	for _, skill := range []*SkillRating{mr.CubeSkill, mr.Skill} {
+		if skill == nil || skill.Rating == "" || skill.Rating == "None" {
+			continue
+		}
+		errs = append(errs, fmt.Sprintf("%.3f", skill.Error))
+		ratings = append(ratings, skill.Rating)
+		if severityRank(skill.Rating) > severityRank(m.Class) {
+			m.Class = skill.Rating
+		}
+	}
+	m.Error = strings.Join(errs, " / ")
+	m.Rating = strings.Join(ratings, " / ")
+	m.Class = strings.ToLower(m.Class)
+	if mr.Luck != nil && mr.Luck.Rating != "" && mr.Luck.Rating != "None" {
+		m.Luck = fmt.Sprintf("%s (%+.3f)", mr.Luck.Rating, mr.Luck.Value)
+	}
+
+	if mr.Type == MoveTypeNormal && mr.Analysis != nil && len(mr.Analysis.Moves) > 0 {
+		best := mr.Analysis.Moves[0].Equity
+		for i, opt := range mr.Analysis.Moves {
+			m.Options = append(m.Options, htmlOption{
+				Rank:   i + 1,
+				Move:   opt.MoveString,
+				Equity: fmt.Sprintf("%+.3f", opt.Equity),
+				Diff:   fmt.Sprintf("%+.3f", opt.Equity-best),
+				Probs: [6]string{
+					htmlPercent(opt.OnRollWin), htmlPercent(opt.OnRollGammon), htmlPercent(opt.OnRollBackgammon),
+					htmlPercent(opt.OpponentWin), htmlPercent(opt.OpponentGammon), htmlPercent(opt.OpponentBackgammon),
+				},
+				Played: i == mr.Analysis.SelectedMove,
+			})
+		}
+	}
+
+	holder := mr.Player
+	if mr.Type == MoveTypeTake || mr.Type == MoveTypeDrop {
+		holder = 1 - mr.Player
+	}
+	if nd, dt, dp, ok := cubeEquities(mr, game, matchLength, holder, cube, met); ok {
+		m.Cube = []htmlCubeRow{
+			{Action: "No double", Equity: fmt.Sprintf("%+.3f", nd)},
+			{Action: "Double, take", Equity: fmt.Sprintf("%+.3f", dt)},
+			{Action: "Double, pass", Equity: fmt.Sprintf("%+.3f", dp)},
+		}
+		proper := properCubeAction(nd, dt, dp)
+		m.Cube[proper.option()].Best = true
+		m.CubeBest = proper.String()
+	}
+	return m
+}
+
+// severityRank orders skill ratings from None to VeryBad
+func severityRank(rating string) int {
+	switch rating {
+	case "Doubtful":
+		return 1
+	case "Bad":
+		return 2
+	case "VeryBad":
+		return 3
+	}
+	return 0
+}
+
+// worstRating returns the most severe flagged rating of a record's checker
+// play and cube skills, or "" when neither is flagged
+func worstRating(mr *MoveRecord) string {
+	worst := ""
+	for _, skill := range []*SkillRating{mr.CubeSkill, mr.Skill} {
+		if skill != nil && severityRank(skill.Rating) > severityRank(worst) {
+			worst = skill.Rating
+		}
+	}
+	return worst
+}
+
+// htmlPercent formats a probability as a percentage
+func htmlPercent(p float32) string {
+	return fmt.Sprintf("%.1f%%", 100*p)
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin: 0.5em 0 1.5em; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: left; }
+th { background: #eee; }
+td.num { text-align: right; font-family: monospace; }
+tr.doubtful td { background: #fff6d5; }
+tr.bad td { background: #ffe0c0; }
+tr.verybad td { background: #ffc8c8; }
+tr.played td { font-weight: bold; }
+tr.best td { font-weight: bold; }
+details table { margin: 0.3em 0; font-size: 90%; }
+.comment { font-style: italic; color: #555; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+{{- range .Meta}}
+<tr><th>{{.Label}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Statistics</h2>
+<table>
+<tr><th></th><th>{{index .Names 0}}</th><th>{{index .Names 1}}</th></tr>
+{{- range .Stats}}
+<tr><th>{{.Label}}</th><td class="num">{{index .Values 0}}</td><td class="num">{{index .Values 1}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Games</h2>
+<table>
+<tr><th>Game</th><th>Score</th><th>Result</th></tr>
+{{- range .Games}}
+<tr><td><a href="#game{{.Number}}">{{.Number}}</a></td><td>{{.Score}}</td><td>{{.Result}}</td></tr>
+{{- end}}
+</table>
+{{range .Games}}
+<h2 id="game{{.Number}}">Game {{.Number}}</h2>
+<p>Score {{.Score}}. {{.Result}}.</p>
+<table>
+<tr><th>#</th><th>Player</th><th>Dice</th><th>Move</th><th>Error</th><th>Rating</th><th>Luck</th><th>Analysis</th></tr>
+{{- range .Moves}}
+<tr{{if .Class}} class="{{.Class}}"{{end}}><td class="num">{{.Number}}</td><td>{{.Player}}</td><td>{{.Dice}}</td><td>{{.Action}}{{if .Comment}}<div class="comment">{{.Comment}}</div>{{end}}</td><td class="num">{{.Error}}</td><td>{{.Rating}}</td><td>{{.Luck}}</td><td>
+{{- if .Cube}}<details><summary>Cube: {{.CubeBest}}</summary><table>
+<tr><th>Action</th><th>Equity</th></tr>
+{{- range .Cube}}
+<tr{{if .Best}} class="best"{{end}}><td>{{.Action}}</td><td class="num">{{.Equity}}</td></tr>
+{{- end}}
+</table></details>{{end}}
+{{- if .Options}}<details><summary>{{len .Options}} moves</summary><table>
+<tr><th>#</th><th>Move</th><th>Equity</th><th>Diff</th><th>Win</th><th>G</th><th>BG</th><th>Opp win</th><th>G</th><th>BG</th></tr>
+{{- range .Options}}
+<tr{{if .Played}} class="played"{{end}}><td class="num">{{.Rank}}</td><td>{{.Move}}</td><td class="num">{{.Equity}}</td><td class="num">{{.Diff}}</td>
+{{- range .Probs}}<td class="num">{{.}}</td>{{end}}</tr>
+{{- end}}
+</table></details>{{end -}}
+</td></tr>
+{{- end}}
+</table>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,55 @@
+package gnubgparser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTML(t *testing.T) {
+	match := &Match{
+		Metadata: MatchMetadata{Player1: "Alice <A>", Player2: "Bob", Event: "Club night"},
+		Games: []Game{{Winner: 0, Points: 2, ResultKind: ResultDroppedDouble, Moves: []MoveRecord{
+			{Type: MoveTypeNormal, Player: 0, Dice: [2]int{3, 1}, MoveString: "8/5 6/5",
+				Analysis: &MoveAnalysis{Moves: []MoveOption{
+					{MoveString: "8/5 6/5", Equity: 0.15, OnRollWin: 0.55},
+					{MoveString: "24/21 6/5", Equity: 0.01},
+				}}},
+			{Type: MoveTypeNormal, Player: 1, Dice: [2]int{6, 5}, MoveString: "24/13",
+				Skill: &SkillRating{Rating: "Bad", Error: 0.09}},
+			{Type: MoveTypeDouble, Player: 0,
+				CubeAnalysis: &CubeAnalysis{CubefulNoDouble: 0.7, CubefulDoubleTake: 0.8, CubefulDoublePass: 0.75}},
+			{Type: MoveTypeDrop, Player: 1,
+				CubeAnalysis: &CubeAnalysis{CubefulNoDouble: 0.7, CubefulDoubleTake: 0.8, CubefulDoublePass: 0.75},
+				Skill:        &SkillRating{Rating: "VeryBad", Error: 0.2}},
+		}}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, match); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"Alice &lt;A&gt; vs Bob",
+		"Club night",
+		"Alice &lt;A&gt; wins 2 points (dropped double)",
+		`<tr class="bad">`,
+		`<tr class="verybad">`,
+		`<tr class="played"><td class="num">1</td><td>8/5 6/5</td>`,
+		"<summary>Cube: Double, pass</summary>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Report does not contain %q", want)
+		}
+	}
+
+	// The report must be self-contained
+	for _, external := range []string{"<link", "<script", "src=", "http"} {
+		if strings.Contains(out, external) {
+			t.Errorf("Report references external assets: %q", external)
+		}
+	}
+}
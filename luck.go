@@ -0,0 +1,135 @@
+package gnubgparser
+
+// PlayerLuck holds one player's luck over a game or a match. Luck is
+// counted for the player who rolled the dice.
+type PlayerLuck struct {
+	Rolls      int     `json:"rolls"`       // Rolls with a luck value
+	Total      float64 `json:"total"`       // Luck in EMG, scaled by the cube value
+	TotalMWC   float64 `json:"total_mwc"`   // Luck in match winning chances (match play)
+	Jokers     int     `json:"jokers"`      // Rolls rated VeryGood
+	AntiJokers int     `json:"anti_jokers"` // Rolls rated VeryBad
+}
+
+// add accumulates another luck total of the same player
+func (l *PlayerLuck) add(o PlayerLuck) {
+	l.Rolls += o.Rolls
+	l.Total += o.Total
+	l.TotalMWC += o.TotalMWC
+	l.Jokers += o.Jokers
+	l.AntiJokers += o.AntiJokers
+}
+
+// LuckReport holds both players' luck and results for a game or a match.
+// Results are in MWC for match play and in points (EMG) for money games.
+type LuckReport struct {
+	Players [2]PlayerLuck `json:"players"`
+	// Actual result: final MWC in match play, net points won in money play
+	Result [2]float64 `json:"result"`
+	// Result minus the player's luck plus the opponent's luck
+	LuckAdjusted [2]float64   `json:"luck_adjusted"`
+	Games        []LuckReport `json:"games,omitempty"` // Per game, for a match
+}
+
+// setLuckAdjusted fills LuckAdjusted from Result and the luck totals
+func (r *LuckReport) setLuckAdjusted(matchPlay bool) {
+	for p := 0; p < 2; p++ {
+		own, opp := r.Players[p].Total, r.Players[1-p].Total
+		if matchPlay {
+			own, opp = r.Players[p].TotalMWC, r.Players[1-p].TotalMWC
+		}
+		r.LuckAdjusted[p] = r.Result[p] - own + opp
+	}
+}
+
+// GameLuck computes both players' luck for a game. In match play the
+// result is the MWC gained or lost in the game, from 0.5 for both players.
+func GameLuck(game *Game, matchLength int) LuckReport {
+	return gameLuck(game, matchLength, nil)
+}
+
+// gameLuck computes GameLuck with the match equity table met
+func gameLuck(game *Game, matchLength int, met *MatchEquityTable) LuckReport {
+	var report LuckReport
+	cubes, _, _ := replayCube(game)
+
+	for i := range game.Moves {
+		mr := &game.Moves[i]
+		if mr.Luck == nil || mr.Type != MoveTypeNormal || mr.Player < 0 || mr.Player > 1 {
+			continue
+		}
+		l := &report.Players[mr.Player]
+		l.Rolls++
+		l.Total += mr.Luck.Value * float64(cubes[i].Value)
+		if matchLength > 0 {
+			l.TotalMWC += met.emgDeltaToMWC(mr.Luck.Value, game, matchLength, mr.Player, cubes[i].Value)
+		}
+		switch mr.Luck.Rating {
+		case "VeryGood":
+			l.Jokers++
+		case "VeryBad":
+			l.AntiJokers++
+		}
+	}
+
+	if game.Winner == 0 || game.Winner == 1 {
+		if matchLength > 0 {
+			// A 1-away score outside the Crawford game is post-Crawford
+			oneAway := hasOneAway(game.Score, matchLength)
+			before := met.equityAt(game.Score, matchLength, oneAway && !game.CrawfordGame, game.Winner)
+			after := game.Score
+			after[game.Winner] += game.Points
+			gain := met.equityAt(after, matchLength, oneAway || game.CrawfordGame, game.Winner) - before
+			report.Result[game.Winner] = 0.5 + gain
+			report.Result[1-game.Winner] = 0.5 - gain
+		} else {
+			report.Result[game.Winner] = float64(game.Points)
+			report.Result[1-game.Winner] = -float64(game.Points)
+		}
+	} else if matchLength > 0 {
+		report.Result = [2]float64{0.5, 0.5}
+	}
+
+	report.setLuckAdjusted(matchLength > 0)
+	return report
+}
+
+// MatchLuck computes both players' luck over a match, with a report per
+// game. In match play the result is the MWC at the final score: 1 or 0
+// once the match is decided.
+func MatchLuck(match *Match) LuckReport {
+	var report LuckReport
+	matchLength := match.Metadata.MatchLength
+	var score [2]int
+	crawfordDone := false
+
+	for i := range match.Games {
+		game := &match.Games[i]
+		gameReport := gameLuck(game, matchLength, match.MET)
+		report.Games = append(report.Games, gameReport)
+		report.Players[0].add(gameReport.Players[0])
+		report.Players[1].add(gameReport.Players[1])
+
+		score = game.Score
+		crawfordDone = crawfordDone || game.CrawfordGame
+		if game.Winner == 0 || game.Winner == 1 {
+			score[game.Winner] += game.Points
+			if matchLength == 0 {
+				report.Result[0] += gameReport.Result[0]
+				report.Result[1] += gameReport.Result[1]
+			}
+		}
+	}
+
+	if matchLength > 0 {
+		report.Result[0] = match.MET.Equity(matchLength-score[0], matchLength-score[1], crawfordDone)
+		report.Result[1] = 1 - report.Result[0]
+	}
+
+	report.setLuckAdjusted(matchLength > 0)
+	return report
+}
+
+// hasOneAway reports whether a player needs a single point
+func hasOneAway(score [2]int, matchLength int) bool {
+	return matchLength-score[0] == 1 || matchLength-score[1] == 1
+}
@@ -0,0 +1,90 @@
+package gnubgparser
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestMoneyLuck(t *testing.T) {
+	roll := func(player int, value float64, rating string) MoveRecord {
+		return MoveRecord{Type: MoveTypeNormal, Player: player, Luck: &LuckRating{Rating: rating, Value: value}}
+	}
+
+	match := &Match{Games: []Game{
+		{Winner: 0, Points: 2, Moves: []MoveRecord{
+			roll(0, 0.7, "VeryGood"),
+			roll(1, -0.1, "None"),
+			{Type: MoveTypeDouble, Player: 1},
+			{Type: MoveTypeTake, Player: 0},
+			// Luck after the take counts at cube 2
+			roll(0, 0.2, "None"),
+		}},
+		{Winner: 1, Points: 1, Moves: []MoveRecord{
+			roll(1, -0.65, "VeryBad"),
+		}},
+	}}
+
+	report := MatchLuck(match)
+	if len(report.Games) != 2 {
+		t.Fatalf("Games = %d, want 2", len(report.Games))
+	}
+
+	p0, p1 := report.Players[0], report.Players[1]
+	if p0.Rolls != 2 || p0.Jokers != 1 || p1.AntiJokers != 1 {
+		t.Errorf("Counts = %+v / %+v", p0, p1)
+	}
+
+	checks := []struct {
+		name      string
+		got, want float64
+	}{
+		{"player 0 luck", p0.Total, 0.7 + 0.4},
+		{"player 1 luck", p1.Total, -0.1 - 0.65},
+		{"player 0 result", report.Result[0], 1},
+		{"player 1 result", report.Result[1], -1},
+		{"player 0 luck-adjusted", report.LuckAdjusted[0], 1 - 1.1 - 0.75},
+		{"player 1 luck-adjusted", report.LuckAdjusted[1], -1 + 0.75 + 1.1},
+		{"game 1 player 0 result", report.Games[0].Result[0], 2},
+	}
+	for _, c := range checks {
+		if math.Abs(c.got-c.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestMatchPlayLuck(t *testing.T) {
+	sgf := `(;FF[4]GM[6]MI[length:3][game:0][ws:0][bs:0]RE[W+3]
+;W[31fehe]LU[0.5]
+;B[41lpab]LU[-inf]
+;W[52lpab]LU[-0.2])
+`
+	match, err := ParseSGF(strings.NewReader(sgf))
+	if err != nil {
+		t.Fatalf("ParseSGF failed: %v", err)
+	}
+
+	report := MatchLuck(match)
+	if report.Result != [2]float64{1, 0} {
+		t.Errorf("Result = %v, want [1 0]", report.Result)
+	}
+	p0 := report.Players[0]
+	if p0.Rolls != 2 || report.Players[1].Rolls != 0 {
+		t.Errorf("Rolls = %d/%d, want 2/0", p0.Rolls, report.Players[1].Rolls)
+	}
+
+	// At 3-away each, winning or losing a point moves the MWC by
+	// less than the full match, so luck in MWC is smaller than in EMG
+	if p0.TotalMWC <= 0 || p0.TotalMWC >= p0.Total {
+		t.Errorf("TotalMWC = %v, want between 0 and %v", p0.TotalMWC, p0.Total)
+	}
+	if got, want := report.LuckAdjusted[0], 1-p0.TotalMWC; math.Abs(got-want) > 1e-9 {
+		t.Errorf("LuckAdjusted = %v, want %v", got, want)
+	}
+
+	stat := match.Games[0].Statistics
+	if !stat.HasDice || stat.Luck[0].Good != 1 || stat.Luck[0].None != 1 {
+		t.Errorf("Luck statistics = %+v", stat.Luck[0])
+	}
+}
@@ -0,0 +1,121 @@
+package gnubgparser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown writes a Markdown report of a match: a metadata table,
+// statistics for both players, and a section per game with its moves, then
+// each flagged decision with a fenced ASCII board and tables of the analysed
+// moves and cube equities. The output depends only on the match, so reports
+// of a re-analysed match diff line by line.
+func WriteMarkdown(w io.Writer, match *Match) error {
+	bw := bufio.NewWriter(w)
+	report := newHTMLReport(match)
+	names := report.Names
+
+	fmt.Fprintf(bw, "# %s\n\n", mdEscape(report.Title))
+	fmt.Fprintln(bw, "| Field | Value |")
+	fmt.Fprintln(bw, "| --- | --- |")
+	for _, f := range report.Meta {
+		fmt.Fprintf(bw, "| %s | %s |\n", f.Label, mdCell(f.Value))
+	}
+
+	fmt.Fprint(bw, "\n## Statistics\n\n")
+	fmt.Fprintf(bw, "| | %s | %s |\n", mdCell(names[0]), mdCell(names[1]))
+	fmt.Fprintln(bw, "| --- | ---: | ---: |")
+	for _, r := range report.Stats {
+		fmt.Fprintf(bw, "| %s | %s | %s |\n", r.Label, r.Values[0], r.Values[1])
+	}
+
+	fmt.Fprint(bw, "\n## Games\n\n")
+	fmt.Fprintln(bw, "| Game | Score | Result |")
+	fmt.Fprintln(bw, "| ---: | --- | --- |")
+	for _, g := range report.Games {
+		fmt.Fprintf(bw, "| [%d](#game-%d) | %s | %s |\n", g.Number, g.Number, g.Score, mdCell(g.Result))
+	}
+
+	for i := range match.Games {
+		writeMarkdownGame(bw, match, i, &report.Games[i], names)
+	}
+	return bw.Flush()
+}
+
+// writeMarkdownGame writes the section of one game
+func writeMarkdownGame(w *bufio.Writer, match *Match, index int, g *htmlGame, names [2]string) {
+	game := &match.Games[index]
+	positions := GamePositions(game, match.Metadata.MatchLength)
+
+	fmt.Fprintf(w, "\n## Game %d\n\n", g.Number)
+	fmt.Fprintf(w, "Score %s. %s.\n\n", g.Score, mdEscape(g.Result))
+	fmt.Fprintln(w, "| # | Player | Dice | Move | Error | Rating | Luck |")
+	fmt.Fprintln(w, "| ---: | --- | --- | --- | ---: | --- | --- |")
+	var flagged []*htmlMove
+	for k := range g.Moves {
+		m := &g.Moves[k]
+		mr := &game.Moves[m.Number-1]
+		action := m.Action
+		if mr.Type == MoveTypeNormal && (mr.Player == 0 || mr.Player == 1) {
+			after := positions[m.Number-1]
+			action = formatHops(after.applyMove(mr.Player, mr.Hops()))
+		}
+		fmt.Fprintf(w, "| %d | %s | %s | %s | %s | %s | %s |\n",
+			m.Number, mdCell(m.Player), m.Dice, mdCell(action), m.Error, m.Rating, m.Luck)
+		if m.Class != "" {
+			flagged = append(flagged, m)
+		}
+	}
+
+	for _, m := range flagged {
+		mr := &game.Moves[m.Number-1]
+		pos := positions[m.Number-1]
+		header, action, ok := textDecision(mr, &pos, names[mr.Player])
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "\n### Move %d: %s\n\n", m.Number, mdEscape(header))
+		fmt.Fprintln(w, "```text")
+		fmt.Fprint(w, pos.RenderASCII(ASCIIOptions{Bottom: mr.Player, Names: names, Checkers: checkersPerSide(game.Variation)}))
+		fmt.Fprintln(w, "```")
+		fmt.Fprintf(w, "\n%s: %s (%s).\n", mdEscape(action), m.Rating, m.Error)
+
+		if len(m.Options) > 0 {
+			fmt.Fprint(w, "\n| # | Move | Equity | Diff | Win | G | BG | Opp win | G | BG |\n")
+			fmt.Fprintln(w, "| ---: | --- | ---: | ---: | ---: | ---: | ---: | ---: | ---: | ---: |")
+			for k, opt := range m.Options {
+				move := mdCell(optionNotation(&mr.Analysis.Moves[k], &pos, mr.Player))
+				if opt.Played {
+					move = "**" + move + "** (played)"
+				}
+				fmt.Fprintf(w, "| %d | %s | %s | %s | %s |\n", opt.Rank, move, opt.Equity, opt.Diff,
+					strings.Join(opt.Probs[:], " | "))
+			}
+		}
+		if len(m.Cube) > 0 {
+			fmt.Fprint(w, "\n| Cube action | Equity |\n")
+			fmt.Fprintln(w, "| --- | ---: |")
+			for _, c := range m.Cube {
+				if c.Best {
+					fmt.Fprintf(w, "| **%s** | **%s** |\n", c.Action, c.Equity)
+				} else {
+					fmt.Fprintf(w, "| %s | %s |\n", c.Action, c.Equity)
+				}
+			}
+		}
+		if m.Comment != "" {
+			fmt.Fprintf(w, "\n> %s\n", strings.ReplaceAll(mdEscape(m.Comment), "\n", "\n> "))
+		}
+	}
+}
+
+// mdEscape escapes the characters that would start Markdown markup in
+// running text
+var mdEscape = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `_`, `\_`, "`", "\\`", `<`, `&lt;`, `[`, `\[`).Replace
+
+// mdCell escapes a table cell, which cannot hold pipes or line breaks
+func mdCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(mdEscape(s))
+}
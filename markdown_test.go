@@ -0,0 +1,57 @@
+package gnubgparser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	match := &Match{
+		Metadata: MatchMetadata{Player1: "Alice", Player2: "Bob|B", Event: "Club night"},
+		Games: []Game{{Winner: 0, Points: 1, ResultKind: ResultDroppedDouble, Moves: []MoveRecord{
+			{Type: MoveTypeNormal, Player: 0, Dice: [2]int{3, 1}, SubMoves: []SubMove{{From: 7, To: 4}, {From: 5, To: 4}}},
+			{Type: MoveTypeNormal, Player: 1, Dice: [2]int{6, 5}, SubMoves: []SubMove{{From: 23, To: 12}},
+				Skill: &SkillRating{Rating: "VeryBad", Error: 0.2}, Comment: "Should *run*",
+				Analysis: &MoveAnalysis{SelectedMove: 1, Moves: []MoveOption{
+					{Move: [8]int{-1}, MoveString: "24/18 13/8", Equity: 0.1},
+					{Move: [8]int{-1}, MoveString: "24/13", Equity: -0.1},
+				}}},
+			{Type: MoveTypeDouble, Player: 0},
+			{Type: MoveTypeDrop, Player: 1},
+		}}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, match); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# Alice vs Bob|B\n",
+		"| Event | Club night |",
+		`| | Alice | Bob\|B |`,
+		"| [1](#game-1) | 0-0 | Alice wins 1 point (dropped double) |",
+		"## Game 1",
+		`| 2 | Bob\|B | 65 | 24/13 | 0.200 | VeryBad |  |`,
+		"### Move 2: Bob|B to play 65\n\n```text\n +13-14-15",
+		"Bob|B moves 24/13: VeryBad (0.200).",
+		"| 1 | 24/18 13/8 | +0.100 | +0.000 |",
+		"| 2 | **24/13** (played) | -0.100 | -0.200 |",
+		`> Should \*run\*`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Report does not contain %q", want)
+		}
+	}
+	if strings.Count(out, "### Move") != 1 {
+		t.Errorf("Only the flagged decision should get a board:\n%s", out)
+	}
+
+	var again bytes.Buffer
+	WriteMarkdown(&again, match)
+	if again.String() != out {
+		t.Errorf("Report is not deterministic")
+	}
+}
@@ -0,0 +1,118 @@
+package gnubgparser
+
+// MatchStatistics aggregates game statistics and results over a match
+type MatchStatistics struct {
+	Games        int              `json:"games"`
+	FinalScore   [2]int           `json:"final_score"`   // Points won by each player
+	Winner       int              `json:"winner"`        // Match winner (-1 = undecided)
+	CrawfordGame int              `json:"crawford_game"` // Index of the Crawford game in Games (-1 = none)
+	Gammons      [2]int           `json:"gammons"`       // Gammons won, resigned gammons included
+	Backgammons  [2]int           `json:"backgammons"`   // Backgammons won, resigned backgammons included
+	Moves        StatisticDetail  `json:"moves"`
+	Cube         StatisticDetail  `json:"cube"`
+	Luck         [2]LuckStatistic `json:"luck"`
+	Performance  Performance      `json:"performance"`
+}
+
+// Statistics sums the statistics of all games and reports the match
+// result. In money play the winner is the player with more points.
+func (m *Match) Statistics() MatchStatistics {
+	stats := MatchStatistics{
+		Games:        len(m.Games),
+		Winner:       -1,
+		CrawfordGame: -1,
+		Performance:  MatchPerformance(m),
+	}
+	matchLength := m.Metadata.MatchLength
+
+	for i := range m.Games {
+		game := &m.Games[i]
+
+		if game.CrawfordGame && stats.CrawfordGame < 0 {
+			stats.CrawfordGame = i
+		}
+
+		stats.Moves.add(game.Statistics.Moves)
+		stats.Cube.add(game.Statistics.Cube)
+		for p := 0; p < 2; p++ {
+			stats.Luck[p].addStatistic(game.Statistics.Luck[p])
+		}
+
+		// In match play each game carries the score before it
+		if matchLength > 0 {
+			stats.FinalScore = game.Score
+		}
+		if game.Winner != 0 && game.Winner != 1 {
+			continue
+		}
+		stats.FinalScore[game.Winner] += game.Points
+		switch resultLevel(game) {
+		case 2:
+			stats.Gammons[game.Winner]++
+		case 3:
+			stats.Backgammons[game.Winner]++
+		}
+	}
+
+	switch {
+	case matchLength > 0:
+		for p := 0; p < 2; p++ {
+			if stats.FinalScore[p] >= matchLength {
+				stats.Winner = p
+			}
+		}
+	case stats.FinalScore[0] > stats.FinalScore[1]:
+		stats.Winner = 0
+	case stats.FinalScore[1] > stats.FinalScore[0]:
+		stats.Winner = 1
+	}
+
+	return stats
+}
+
+// resultLevel returns 1 for a single game, 2 for a gammon and 3 for a
+// backgammon, counting the level of accepted resignations
+func resultLevel(game *Game) int {
+	switch game.ResultKind {
+	case ResultGammon:
+		return 2
+	case ResultBackgammon:
+		return 3
+	case ResultResignation:
+		if resign := lastResignation(game); resign != nil && resign.ResignLevel > 0 {
+			return resign.ResignLevel
+		}
+		if cube, _ := finalCubeValue(game); game.Points%cube == 0 && game.Points/cube <= 3 && game.Points > 0 {
+			return game.Points / cube
+		}
+	}
+	return 1
+}
+
+// add accumulates another statistic detail
+func (s *StatisticDetail) add(o StatisticDetail) {
+	for p := 0; p < 2; p++ {
+		s.Unforced[p] += o.Unforced[p]
+		s.Forced[p] += o.Forced[p]
+		s.VeryBad[p] += o.VeryBad[p]
+		s.Bad[p] += o.Bad[p]
+		s.Doubtful[p] += o.Doubtful[p]
+		s.ErrorTotal[p] += o.ErrorTotal[p]
+		s.ErrorSkill[p] += o.ErrorSkill[p]
+		s.MissedDouble[p] += o.MissedDouble[p]
+		s.WrongDouble[p] += o.WrongDouble[p]
+		s.WrongTake[p] += o.WrongTake[p]
+		s.WrongPass[p] += o.WrongPass[p]
+	}
+}
+
+// addStatistic accumulates another luck statistic of the same player
+func (l *LuckStatistic) addStatistic(o LuckStatistic) {
+	l.VeryBad += o.VeryBad
+	l.Bad += o.Bad
+	l.None += o.None
+	l.Good += o.Good
+	l.VeryGood += o.VeryGood
+	l.Total += o.Total
+	l.TotalSq += o.TotalSq
+}
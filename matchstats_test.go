@@ -0,0 +1,61 @@
+package gnubgparser
+
+import "testing"
+
+func TestMatchStatistics(t *testing.T) {
+	for _, file := range []string{
+		"test/charlot1-charlot2_7p_2025-11-08-2305.sgf",
+		"test/charlot1-charlot2_7p_2025-11-08-2305.mat",
+	} {
+		t.Run(file, func(t *testing.T) {
+			var match *Match
+			var err error
+			if file[len(file)-4:] == ".mat" {
+				match, err = ParseMATFile(file)
+			} else {
+				match, err = ParseSGFFile(file)
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse file: %v", err)
+			}
+
+			stats := match.Statistics()
+			if stats.Games != 4 {
+				t.Errorf("Games = %d, want 4", stats.Games)
+			}
+			if stats.FinalScore != [2]int{9, 2} || stats.Winner != 0 {
+				t.Errorf("Final score = %v, winner = %d, want [9 2] and 0", stats.FinalScore, stats.Winner)
+			}
+			if stats.CrawfordGame != 3 {
+				t.Errorf("CrawfordGame = %d, want 3", stats.CrawfordGame)
+			}
+			if stats.Gammons != [2]int{1, 0} || stats.Backgammons != [2]int{1, 0} {
+				t.Errorf("Gammons = %v, backgammons = %v", stats.Gammons, stats.Backgammons)
+			}
+
+			unforced := 0
+			for _, game := range match.Games {
+				unforced += game.Statistics.Moves.Unforced[0]
+			}
+			if stats.Moves.Unforced[0] != unforced {
+				t.Errorf("Moves.Unforced = %d, want %d", stats.Moves.Unforced[0], unforced)
+			}
+		})
+	}
+}
+
+func TestMoneyMatchStatistics(t *testing.T) {
+	match := &Match{Games: []Game{
+		{Winner: 1, Points: 2, ResultKind: ResultGammon},
+		{Winner: 0, Points: 1, ResultKind: ResultSingle},
+		{Winner: -1},
+	}}
+
+	stats := match.Statistics()
+	if stats.FinalScore != [2]int{1, 2} || stats.Winner != 1 {
+		t.Errorf("Final score = %v, winner = %d, want [1 2] and 1", stats.FinalScore, stats.Winner)
+	}
+	if stats.CrawfordGame != -1 || stats.Gammons != [2]int{0, 1} {
+		t.Errorf("CrawfordGame = %d, gammons = %v", stats.CrawfordGame, stats.Gammons)
+	}
+}
@@ -0,0 +1,187 @@
+package gnubgparser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MATDialect identifies the program that produced a MAT file.
+// The layouts differ in column widths, keywords and separators.
+type MATDialect string
+
+const (
+	MATDialectJellyfish  MATDialect = "jellyfish"  // Jellyfish layout, also written by gnuBG
+	MATDialectXG         MATDialect = "xg"         // eXtremeGammon export
+	MATDialectGridGammon MATDialect = "gridgammon" // GridGammon / GamesGrid export
+	MATDialectFIBS       MATDialect = "fibs"       // FIBS client match logs
+	MATDialectBGOnline   MATDialect = "bgonline"   // BGOnline export
+)
+
+// matDialectRules holds the per-dialect rules used while parsing games.
+type matDialectRules struct {
+	// Column where the right player's entry starts, relative to the text
+	// after "N)". 0 means unknown: fall back to splitMoveLine.
+	rightColumn int
+	// Columns are separated by tabs instead of padding spaces
+	tabSeparated bool
+	// Cube action and move keywords
+	doublesRe  *regexp.Regexp
+	takesRe    *regexp.Regexp
+	dropsRe    *regexp.Regexp
+	beaversRe  *regexp.Regexp // nil when the dialect never writes beavers
+	cantMoveRe *regexp.Regexp
+	// Resignation keywords
+	resignsRe *regexp.Regexp
+	acceptsRe *regexp.Regexp
+	rejectsRe *regexp.Regexp
+	// Start of a column entry, used to sanity-check fixed-column splits
+	entryStartRe *regexp.Regexp
+}
+
+// Keywords shared by all dialects. Localized Jellyfish builds translate
+// the keywords; the French, German and Spanish spellings are accepted too.
+const (
+	matDoublesWords  = `Doubles|Double|Verdoppelt|Dobla`
+	matTakesWords    = `Takes|Take|Prend|Nimmt(?:\s+an)?|Acepta`
+	matDropsWords    = `Drops|Drop|Passes|Pass|Passe|Abgelehnt|Rechaza`
+	matBeaversWords  = `Beavers|Beaver`
+	matResignsWords  = `Resigns|Resign|Gibt\s+auf|Abandonne`
+	matAcceptsWords  = `Accepts|Accept|Akzeptiert|Accepte`
+	matRejectsWords  = `Rejects|Reject|Lehnt\s+ab|Refuse`
+	matCantMoveWords = `Can't\s+move|Cannot\s+move|Cant\s+move|Kann\s+nicht\s+ziehen|Ne\s+peut\s+pas\s+jouer`
+)
+
+// Keyword patterns shared by all dialects
+var (
+	matDoublesRe  = regexp.MustCompile(`^(?i:` + matDoublesWords + `)\s*=>\s*(\d+)\s*$`)
+	matTakesRe    = regexp.MustCompile(`^(?i:` + matTakesWords + `)\s*$`)
+	matDropsRe    = regexp.MustCompile(`^(?i:` + matDropsWords + `)\s*$`)
+	matBeaversRe  = regexp.MustCompile(`^(?i:` + matBeaversWords + `)\s*=>\s*(\d+)\s*$`)
+	matCantMoveRe = regexp.MustCompile(`^(?i:` + matCantMoveWords + `)\.?$`)
+	// "Resigns", "Resigns gammon" or "Resigns 2 points"
+	matResignsRe    = regexp.MustCompile(`^(?i:` + matResignsWords + `)(?:\s+(\d+)\s+points?|\s+(\w+))?\s*$`)
+	matAcceptsRe    = regexp.MustCompile(`^(?i:` + matAcceptsWords + `)\s*$`)
+	matRejectsRe    = regexp.MustCompile(`^(?i:` + matRejectsWords + `)\s*$`)
+	matEntryStartRe = regexp.MustCompile(`^\s?(\d\d:|(?i:` + matDoublesWords + `|` + matTakesWords + `|` + matDropsWords + `|` + matBeaversWords + `|` + matResignsWords + `|` + matAcceptsWords + `|` + matRejectsWords + `|Wins)\b)`)
+	// Dice entry of the right player after the column padding
+	matRightDiceRe = regexp.MustCompile(`\S\s{2,}(\d\d:)`)
+)
+
+// Header cues identifying each dialect, checked against comment lines.
+// Player tags alone are no cue: BGBlitz writes them too.
+var matDialectCues = []struct {
+	dialect MATDialect
+	re      *regexp.Regexp
+}{
+	{MATDialectXG, regexp.MustCompile(`(?i)extremegammon|\bXG\b`)},
+	{MATDialectGridGammon, regexp.MustCompile(`(?i)gridgammon|gamesgrid`)},
+	{MATDialectBGOnline, regexp.MustCompile(`(?i)bgonline|backgammon\s+online`)},
+	{MATDialectFIBS, regexp.MustCompile(`(?i)\bfibs\b|fibs\.com`)},
+}
+
+// detectMATDialect guesses the producing program from header comments,
+// defaulting to the Jellyfish layout when no cue is present.
+func detectMATDialect(comments []string) MATDialect {
+	for _, cue := range matDialectCues {
+		for _, c := range comments {
+			if cue.re.MatchString(c) {
+				return cue.dialect
+			}
+		}
+	}
+	return MATDialectJellyfish
+}
+
+// newMATDialectRules builds the parsing rules for a dialect, refined with
+// layout cues measured on the first move lines of the file. GridGammon and
+// BGOnline write the Jellyfish layout and share its rules.
+func newMATDialectRules(dialect MATDialect, moveContents []string) *matDialectRules {
+	rules := &matDialectRules{
+		doublesRe:    matDoublesRe,
+		takesRe:      matTakesRe,
+		dropsRe:      matDropsRe,
+		cantMoveRe:   matCantMoveRe,
+		resignsRe:    matResignsRe,
+		acceptsRe:    matAcceptsRe,
+		rejectsRe:    matRejectsRe,
+		entryStartRe: matEntryStartRe,
+	}
+
+	// Only XG offers beavers in its MAT export
+	if dialect == MATDialectXG {
+		rules.beaversRe = matBeaversRe
+	}
+
+	rules.tabSeparated, rules.rightColumn = measureMATLayout(moveContents)
+
+	// FIBS logs pad columns by player name length, so a fixed column
+	// measured on one game does not hold for the next one.
+	if dialect == MATDialectFIBS {
+		rules.rightColumn = 0
+	}
+
+	return rules
+}
+
+// measureMATLayout inspects move line contents (the text after "N)") and
+// reports whether columns are tab-separated and, for space-padded layouts,
+// the most common column at which the right player's dice entry starts.
+func measureMATLayout(moveContents []string) (bool, int) {
+	tabs := 0
+	columns := make(map[int]int)
+
+	for _, content := range moveContents {
+		if strings.Contains(content, "\t") {
+			tabs++
+			continue
+		}
+
+		trimmed := strings.TrimLeft(content, " ")
+		indent := len(content) - len(trimmed)
+		if indent >= 10 && diceAndMoveRe.MatchString(trimmed) {
+			// Left column empty: the right entry starts at the indent
+			columns[indent]++
+		} else if loc := matRightDiceRe.FindStringSubmatchIndex(content); loc != nil {
+			columns[loc[2]]++
+		}
+	}
+
+	if tabs > 0 && tabs*2 >= len(moveContents) {
+		return true, 0
+	}
+
+	best, bestCount := 0, 0
+	for col, count := range columns {
+		if count > bestCount || (count == bestCount && col < best) {
+			best, bestCount = col, count
+		}
+	}
+	return false, best
+}
+
+// split divides the text after "N)" into the left (player 1) and right
+// (player 2) entries using the dialect's column rules.
+func (r *matDialectRules) split(content string) [2]string {
+	if r.tabSeparated && strings.Contains(content, "\t") {
+		fields := strings.Split(content, "\t")
+		if len(fields) > 1 && strings.TrimSpace(fields[0]) == "" {
+			fields = fields[1:]
+		}
+		var result [2]string
+		result[0] = strings.TrimSpace(fields[0])
+		if len(fields) > 1 {
+			result[1] = strings.TrimSpace(strings.Join(fields[1:], " "))
+		}
+		return result
+	}
+
+	if c := r.rightColumn; c > 0 && len(content) > c && content[c-1] == ' ' &&
+		r.entryStartRe.MatchString(content[c:]) {
+		return [2]string{
+			strings.TrimSpace(content[:c]),
+			strings.TrimSpace(content[c:]),
+		}
+	}
+
+	return splitMoveLine(content)
+}
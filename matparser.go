@@ -2,6 +2,7 @@ package gnubgparser
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -12,10 +13,14 @@ import (
 
 // MATParser handles parsing of Jellyfish .mat files
 type MATParser struct {
-	scanner    *bufio.Scanner
-	lineNum    int
-	peekedLine string
-	hasPeeked  bool
+	scanner *bufio.Scanner
+	lineNum int
+	pending []string // Lines read ahead or pushed back, returned before the scanner's
+	// Game rules announced in header tags, applied to every game
+	variation string
+	jacoby    bool // Money sessions play Jacoby unless a tag turns it off
+	// Column and keyword rules of the detected dialect
+	rules *matDialectRules
 }
 
 // NewMATParser creates a new MAT parser from a reader
@@ -25,12 +30,13 @@ func NewMATParser(r io.Reader) *MATParser {
 	}
 }
 
-// nextLine returns the next line, using the peeked line if available.
+// nextLine returns the next line, using pending lines first if available.
 func (p *MATParser) nextLine() (string, bool) {
-	if p.hasPeeked {
-		p.hasPeeked = false
+	if len(p.pending) > 0 {
+		line := p.pending[0]
+		p.pending = p.pending[1:]
 		p.lineNum++
-		return p.peekedLine, true
+		return line, true
 	}
 	if p.scanner.Scan() {
 		p.lineNum++
@@ -41,11 +47,21 @@ func (p *MATParser) nextLine() (string, bool) {
 
 // unreadLine pushes a line back so the next call to nextLine returns it.
 func (p *MATParser) unreadLine(line string) {
-	p.peekedLine = line
-	p.hasPeeked = true
+	p.pending = append([]string{line}, p.pending...)
 	p.lineNum-- // will be re-incremented on next read
 }
 
+// lookahead returns up to n upcoming lines without consuming them.
+func (p *MATParser) lookahead(n int) []string {
+	for len(p.pending) < n && p.scanner.Scan() {
+		p.pending = append(p.pending, p.scanner.Text())
+	}
+	if len(p.pending) < n {
+		n = len(p.pending)
+	}
+	return p.pending[:n]
+}
+
 // ParseMATFile parses a .mat file and returns a Match
 func ParseMATFile(filename string) (*Match, error) {
 	file, err := os.Open(filename)
@@ -57,12 +73,70 @@ func ParseMATFile(filename string) (*Match, error) {
 	return ParseMAT(file)
 }
 
-// ParseMAT parses MAT data from a reader and returns a Match
+// ParseMAT parses MAT data from a reader and returns a Match.
+// If the data holds several matches only the first one is returned;
+// use ParseAllMAT to get all of them.
 func ParseMAT(r io.Reader) (*Match, error) {
 	parser := NewMATParser(r)
 	return parser.parse()
 }
 
+// ParseAllMATFile parses a .mat file that may hold several matches
+func ParseAllMATFile(filename string) ([]*Match, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return ParseAllMAT(file)
+}
+
+// ParseAllMAT parses MAT data holding one or more concatenated matches,
+// as found in tournament dumps. A new match starts at each "N point match"
+// header, together with the comment lines just before it.
+func ParseAllMAT(r io.Reader) ([]*Match, error) {
+	parser := NewMATParser(r)
+
+	var matches []*Match
+	for !parser.atEOF() {
+		match, err := parser.parse()
+		if errors.Is(err, errNoMatchHeader) && len(matches) > 0 {
+			// Trailing text after the last match
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("match %d: %w", len(matches)+1, err)
+		}
+		matches = append(matches, match)
+	}
+
+	if len(matches) == 0 {
+		return nil, errNoMatchHeader
+	}
+
+	return matches, nil
+}
+
+// atEOF reports whether only blank lines remain in the input.
+func (p *MATParser) atEOF() bool {
+	for n := 1; ; n++ {
+		lines := p.lookahead(n)
+		if len(lines) < n {
+			return true
+		}
+		if strings.TrimSpace(lines[n-1]) != "" {
+			return false
+		}
+	}
+}
+
+// Number of lines after the match header inspected for layout cues
+const matLayoutSampleLines = 60
+
+// errNoMatchHeader is returned when no "N point match" header is found
+var errNoMatchHeader = errors.New("invalid MAT file: no match header found")
+
 // Regular expressions for MAT format parsing
 var (
 	// Match header: " 7 point match"
@@ -82,25 +156,17 @@ var (
 	// Comment line starting with ; or #
 	commentLineRe = regexp.MustCompile(`^\s*[;#]\s*(.*)$`)
 
-	// EventDate comment: "; [EventDate "2025.11.08"]"
-	eventDateRe = regexp.MustCompile(`\[EventDate\s+"(\d{4})\.(\d{2})\.(\d{2})"\]`)
+	// Header tag comment: "; [EventDate "2025.11.08"]", "; [Player 1 Elo "1500.00/0"]" or "; [Unrated]"
+	headerTagRe = regexp.MustCompile(`\[([A-Za-z][A-Za-z0-9 ]*?)(?:\s+"([^"]*)")?\]`)
 
-	// Other metadata comments
-	eventRe       = regexp.MustCompile(`\[Event\s+"([^"]+)"\]`)
-	roundRe       = regexp.MustCompile(`\[Round\s+"([^"]+)"\]`)
-	siteRe        = regexp.MustCompile(`\[Site\s+"([^"]+)"\]`)
-	transcriberRe = regexp.MustCompile(`\[Transcriber\s+"([^"]+)"\]`)
+	// EventDate value: "2025.11.08"
+	eventDateRe = regexp.MustCompile(`^(\d{4})\.(\d{2})\.(\d{2})$`)
 
 	// Wins line: "                                  Wins 2 points" or "Wins 2 points and the match"
 	winsLineRe = regexp.MustCompile(`^\s*Wins\s+(\d+)\s+points?(?:\s+and\s+the\s+match)?\s*$`)
 
 	// Dice and move: "31: 6/5 8/5" or "41: 13/9 24/23"
 	diceAndMoveRe = regexp.MustCompile(`^(\d)(\d):\s*(.*)$`)
-
-	// Cube actions
-	doublesRe = regexp.MustCompile(`^Doubles\s*=>\s*(\d+)\s*$`)
-	takesRe   = regexp.MustCompile(`^Takes\s*$`)
-	dropsRe   = regexp.MustCompile(`^Drops\s*$`)
 )
 
 // parse parses the entire MAT file
@@ -109,9 +175,12 @@ func (p *MATParser) parse() (*Match, error) {
 		Metadata: MatchMetadata{},
 		Games:    []Game{},
 	}
+	p.variation = ""
+	p.jacoby = true
 
-	// Parse comments and match header
-	matchLength := 0
+	// Parse comments and match header; money sessions have a 0 point header
+	matchLength := -1
+	var comments []string
 	for {
 		line, ok := p.nextLine()
 		if !ok {
@@ -121,6 +190,7 @@ func (p *MATParser) parse() (*Match, error) {
 		// Check for comments with metadata
 		if matches := commentLineRe.FindStringSubmatch(line); matches != nil {
 			p.parseMetadataComment(match, matches[1])
+			comments = append(comments, matches[1])
 			continue
 		}
 
@@ -133,9 +203,20 @@ func (p *MATParser) parse() (*Match, error) {
 		}
 	}
 
-	if matchLength == 0 {
-		return nil, fmt.Errorf("invalid MAT file: no match header found")
+	if matchLength < 0 {
+		return nil, errNoMatchHeader
+	}
+
+	// Detect the producing dialect from the header and the layout of the
+	// first move lines
+	var moveContents []string
+	for _, line := range p.lookahead(matLayoutSampleLines) {
+		if matches := moveLineRe.FindStringSubmatch(line); matches != nil {
+			moveContents = append(moveContents, matches[2])
+		}
 	}
+	match.Dialect = detectMATDialect(comments)
+	p.rules = newMATDialectRules(match.Dialect, moveContents)
 
 	// Parse games
 	for {
@@ -155,31 +236,119 @@ func (p *MATParser) parse() (*Match, error) {
 		return nil, fmt.Errorf("no games found in MAT file")
 	}
 
+	// The Crawford game follows from the score sequence
+	deriveCrawford(match, false)
+	checkGames(match)
+
 	return match, nil
 }
 
-// parseMetadataComment extracts metadata from comment lines
+// parseMetadataComment extracts metadata from comment lines.
+// Tag names are matched case-insensitively and ignoring spaces, so
+// "Player 1 Elo" and "player1elo" are equivalent. Tags that are not
+// recognised are kept in MatchMetadata.Tags.
 func (p *MATParser) parseMetadataComment(match *Match, comment string) {
-	if matches := eventDateRe.FindStringSubmatch(comment); matches != nil {
-		year, _ := strconv.Atoi(matches[1])
-		month, _ := strconv.Atoi(matches[2])
-		day, _ := strconv.Atoi(matches[3])
-		match.Metadata.Date = fmt.Sprintf("%04d-%02d-%02d", year, month, day)
-	} else if matches := eventRe.FindStringSubmatch(comment); matches != nil {
-		match.Metadata.Event = matches[1]
-	} else if matches := roundRe.FindStringSubmatch(comment); matches != nil {
-		match.Metadata.Round = matches[1]
-	} else if matches := siteRe.FindStringSubmatch(comment); matches != nil {
-		match.Metadata.Place = matches[1]
-	} else if matches := transcriberRe.FindStringSubmatch(comment); matches != nil {
-		match.Metadata.Annotator = matches[1]
+	for _, matches := range headerTagRe.FindAllStringSubmatch(comment, -1) {
+		name := strings.TrimSpace(matches[1])
+		value := matches[2]
+		md := &match.Metadata
+
+		switch strings.ToLower(strings.ReplaceAll(name, " ", "")) {
+		case "eventdate":
+			if dm := eventDateRe.FindStringSubmatch(value); dm != nil {
+				year, _ := strconv.Atoi(dm[1])
+				month, _ := strconv.Atoi(dm[2])
+				day, _ := strconv.Atoi(dm[3])
+				md.Date = fmt.Sprintf("%04d-%02d-%02d", year, month, day)
+			} else {
+				setMetadataTag(md, name, value)
+			}
+		case "eventtime":
+			md.EventTime = value
+		case "event":
+			md.Event = value
+		case "round":
+			md.Round = value
+		case "site":
+			md.Place = value
+		case "transcriber", "annotator":
+			md.Annotator = value
+		case "player1":
+			md.Player1 = value
+		case "player2":
+			md.Player2 = value
+		case "player1elo", "player1rating":
+			md.Rating1 = value
+		case "player2elo", "player2rating":
+			md.Rating2 = value
+		case "variation":
+			p.variation = parseMatVariation(value)
+		case "jacoby":
+			p.jacoby = parseMatOnOff(value)
+		case "beaver", "beavers":
+			md.Beaver = parseMatOnOff(value)
+		case "cubelimit":
+			if limit, err := strconv.Atoi(value); err == nil {
+				md.CubeLimit = limit
+			} else {
+				setMetadataTag(md, name, value)
+			}
+		case "unrated":
+			md.Unrated = parseMatOnOff(value)
+		default:
+			setMetadataTag(md, name, value)
+		}
+	}
+}
+
+// setMetadataTag records a header tag that has no dedicated field.
+func setMetadataTag(md *MatchMetadata, name, value string) {
+	if md.Tags == nil {
+		md.Tags = make(map[string]string)
 	}
+	md.Tags[name] = value
+}
+
+// parseMatOnOff interprets a header flag value. A bare tag such as
+// "[Unrated]" has an empty value and counts as on.
+func parseMatOnOff(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "on", "yes", "true", "1":
+		return true
+	}
+	return false
+}
+
+// parseMatVariation maps a MAT [Variation] value to Game.Variation naming.
+func parseMatVariation(value string) string {
+	switch strings.ToLower(strings.ReplaceAll(value, " ", "")) {
+	case "", "backgammon", "standard":
+		return "Standard"
+	case "nackgammon":
+		return "Nackgammon"
+	case "hypergammon", "hypergammon3":
+		return "Hypergammon3"
+	case "hypergammon1":
+		return "Hypergammon1"
+	case "hypergammon2":
+		return "Hypergammon2"
+	}
+	return value
+}
+
+// splitPlayerRating splits a score-line player field such as
+// "charlot1, 1623.45" into the name and the rating suffix.
+func splitPlayerRating(field string) (string, string) {
+	name, rating, _ := strings.Cut(field, ",")
+	return strings.TrimSpace(name), strings.TrimSpace(rating)
 }
 
 // parseGame parses a single game
 func (p *MATParser) parseGame(matchLength int, match *Match) (*Game, error) {
-	// Find game header
+	// Find game header. A match header ends the current match; the
+	// comment lines right before it belong to the next match's header.
 	var gameNumber int
+	var comments []string
 	for {
 		line, ok := p.nextLine()
 		if !ok {
@@ -191,6 +360,20 @@ func (p *MATParser) parseGame(matchLength int, match *Match) (*Game, error) {
 			gameNumber = num
 			break
 		}
+
+		if matchHeaderRe.MatchString(line) {
+			p.unreadLine(line)
+			for i := len(comments) - 1; i >= 0; i-- {
+				p.unreadLine(comments[i])
+			}
+			return nil, io.EOF
+		}
+
+		if commentLineRe.MatchString(line) {
+			comments = append(comments, line)
+		} else if strings.TrimSpace(line) != "" {
+			comments = nil
+		}
 	}
 
 	if gameNumber == 0 {
@@ -213,39 +396,44 @@ func (p *MATParser) parseGame(matchLength int, match *Match) (*Game, error) {
 	player2 := strings.TrimSpace(matches[3])
 	score2, _ := strconv.Atoi(matches[4])
 
-	// Update match metadata with player names (from first game)
+	// Update match metadata with player names and ratings (from first game).
+	// Header tags such as [Player 1 "..."] take precedence over the score line.
 	if gameNumber == 1 {
-		// Remove trailing commas and ratings if present
-		player1Clean := strings.Split(player1, ",")[0]
-		player2Clean := strings.Split(player2, ",")[0]
-		match.Metadata.Player1 = strings.TrimSpace(player1Clean)
-		match.Metadata.Player2 = strings.TrimSpace(player2Clean)
+		name1, rating1 := splitPlayerRating(player1)
+		name2, rating2 := splitPlayerRating(player2)
+		if match.Metadata.Player1 == "" {
+			match.Metadata.Player1 = name1
+		}
+		if match.Metadata.Player2 == "" {
+			match.Metadata.Player2 = name2
+		}
+		if match.Metadata.Rating1 == "" {
+			match.Metadata.Rating1 = rating1
+		}
+		if match.Metadata.Rating2 == "" {
+			match.Metadata.Rating2 = rating2
+		}
+	}
+
+	variation := p.variation
+	if variation == "" {
+		variation = "Standard"
 	}
 
 	game := &Game{
 		GameNumber:  gameNumber,
 		Score:       [2]int{score1, score2},
-		Variation:   "Standard",
+		Variation:   variation,
 		Crawford:    matchLength > 0,
-		Jacoby:      matchLength == 0,
+		Jacoby:      matchLength == 0 && p.jacoby,
 		CubeEnabled: true,
 		Winner:      -1,
 		Moves:       []MoveRecord{},
 	}
 
-	// Determine if this is Crawford game
-	if matchLength > 0 {
-		if score1 == matchLength-1 && score2 < matchLength-1 {
-			game.CrawfordGame = true
-		} else if score2 == matchLength-1 && score1 < matchLength-1 {
-			game.CrawfordGame = true
-		}
-	}
-
 	// Parse moves
 	currentPlayer := 1 // Start with player 2 (1-indexed in MAT format)
 	cubeValue := 1
-	_ = cubeValue // Will be used for cube tracking in future
 	gameEnded := false
 
 	for {
@@ -268,8 +456,9 @@ func (p *MATParser) parseGame(matchLength int, match *Match) (*Game, error) {
 			continue
 		}
 
-		// Check for next game starting - unread the line so the next parseGame finds it
-		if gameHeaderRe.MatchString(line) {
+		// Check for next game or match starting - unread the line so the next
+		// parseGame finds it
+		if gameHeaderRe.MatchString(line) || matchHeaderRe.MatchString(line) {
 			p.unreadLine(line)
 			break
 		}
@@ -280,7 +469,7 @@ func (p *MATParser) parseGame(matchLength int, match *Match) (*Game, error) {
 			moveContent := matches[2]
 
 			// Split into left and right parts (player 1 and player 2)
-			parts := splitMoveLine(moveContent)
+			parts := p.rules.split(moveContent)
 
 			for i, part := range parts {
 				if part == "" {
@@ -299,7 +488,7 @@ func (p *MATParser) parseGame(matchLength int, match *Match) (*Game, error) {
 				}
 
 				// Check for cube actions first (they don't have dice)
-				if matches := doublesRe.FindStringSubmatch(part); matches != nil {
+				if matches := p.rules.doublesRe.FindStringSubmatch(part); matches != nil {
 					newCube, _ := strconv.Atoi(matches[1])
 					move := MoveRecord{
 						Type:      MoveTypeDouble,
@@ -311,7 +500,20 @@ func (p *MATParser) parseGame(matchLength int, match *Match) (*Game, error) {
 					continue
 				}
 
-				if takesRe.MatchString(part) {
+				// A beaver accepts the double and immediately redoubles,
+				// keeping the cube; the original doubler's "Takes" follows.
+				if p.rules.beaversRe != nil {
+					if matches := p.rules.beaversRe.FindStringSubmatch(part); matches != nil {
+						newCube, _ := strconv.Atoi(matches[1])
+						game.Moves = append(game.Moves,
+							MoveRecord{Type: MoveTypeDouble, Player: player, CubeValue: newCube, Comment: "Beaver"})
+						cubeValue *= 2
+						currentPlayer = player
+						continue
+					}
+				}
+
+				if p.rules.takesRe.MatchString(part) {
 					move := MoveRecord{
 						Type:   MoveTypeTake,
 						Player: player,
@@ -322,7 +524,7 @@ func (p *MATParser) parseGame(matchLength int, match *Match) (*Game, error) {
 					continue
 				}
 
-				if dropsRe.MatchString(part) {
+				if p.rules.dropsRe.MatchString(part) {
 					move := MoveRecord{
 						Type:   MoveTypeDrop,
 						Player: player,
@@ -335,18 +537,58 @@ func (p *MATParser) parseGame(matchLength int, match *Match) (*Game, error) {
 					break
 				}
 
+				// Resignation offers and answers
+				if matches := p.rules.resignsRe.FindStringSubmatch(part); matches != nil {
+					move := MoveRecord{
+						Type:   MoveTypeResign,
+						Player: player,
+					}
+					if n, err := strconv.Atoi(matches[1]); err == nil && n%cubeValue == 0 {
+						move.ResignLevel = n / cubeValue
+					} else if matches[2] != "" {
+						move.ResignLevel = parseResignLevel(matches[2])
+					}
+					game.Moves = append(game.Moves, move)
+					// A following standalone "Wins" line belongs to the opponent
+					currentPlayer = 1 - player
+					continue
+				}
+
+				if p.rules.acceptsRe.MatchString(part) {
+					game.Moves = append(game.Moves, MoveRecord{Type: MoveTypeAccept, Player: player})
+					currentPlayer = player
+					continue
+				}
+
+				if p.rules.rejectsRe.MatchString(part) {
+					game.Moves = append(game.Moves, MoveRecord{Type: MoveTypeReject, Player: player})
+					currentPlayer = player
+					continue
+				}
+
 				// Check for dice and move
 				if matches := diceAndMoveRe.FindStringSubmatch(part); matches != nil {
 					die1, _ := strconv.Atoi(matches[1])
 					die2, _ := strconv.Atoi(matches[2])
 					moveStr := strings.TrimSpace(matches[3])
 
+					// Always parse; empty string returns all -1 (no move)
+					var subMoves []SubMove
+					var warnings []string
+					if !p.rules.cantMoveRe.MatchString(moveStr) {
+						subMoves, warnings = parseMatSubMoves(moveStr)
+					}
+					for _, w := range warnings {
+						match.Warnings = append(match.Warnings, fmt.Sprintf("line %d: %s", p.lineNum, w))
+					}
+
 					move := MoveRecord{
 						Type:       MoveTypeNormal,
 						Player:     player,
 						Dice:       [2]int{die1, die2},
 						MoveString: moveStr,
-						Move:       parseMatMove(moveStr), // Always parse; empty string returns all -1 (no move)
+						Move:       encodeSubMoves(subMoves),
+						SubMoves:   subMoves,
 					}
 
 					game.Moves = append(game.Moves, move)
@@ -356,6 +598,10 @@ func (p *MATParser) parseGame(matchLength int, match *Match) (*Game, error) {
 		}
 
 		if gameEnded {
+			// A drop ends the game before its "Wins N points" line
+			if game.Points == 0 {
+				p.readWinsLine(game)
+			}
 			break
 		}
 	}
@@ -363,6 +609,26 @@ func (p *MATParser) parseGame(matchLength int, match *Match) (*Game, error) {
 	return game, nil
 }
 
+// readWinsLine consumes a "Wins N points" line following the end of a game,
+// skipping blank lines. Any other line is pushed back.
+func (p *MATParser) readWinsLine(game *Game) {
+	for {
+		line, ok := p.nextLine()
+		if !ok {
+			return
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if matches := winsLineRe.FindStringSubmatch(line); matches != nil {
+			game.Points, _ = strconv.Atoi(matches[1])
+			return
+		}
+		p.unreadLine(line)
+		return
+	}
+}
+
 // splitMoveLine splits a move line into left (player1) and right (player2) parts
 // MAT format uses multiple spaces (typically 3+) to separate the two player columns.
 // When the left column has a long move (e.g., 4-submove doubles like "66: 22/16 22/16 16/10 16/10"),
@@ -422,57 +688,109 @@ func splitMoveLine(line string) [2]string {
 
 // parseMatMove converts MAT move notation to internal format
 // MAT format: "6/5 8/5" or "13/9 24/23" or "bar/23" or "18/16(2) 6/4(2)"
-// The (N) suffix means the movement is repeated N times
+// The (N) suffix means the movement is repeated N times.
+// Chained moves such as "24/18/13" are expanded into one entry per hop.
 func parseMatMove(moveStr string) [8]int {
-	move := [8]int{-1, -1, -1, -1, -1, -1, -1, -1}
+	subMoves, _ := parseMatSubMoves(moveStr)
+	return encodeSubMoves(subMoves)
+}
 
-	if moveStr == "" || strings.Contains(strings.ToLower(moveStr), "can't move") ||
-		strings.Contains(strings.ToLower(moveStr), "cannot move") {
-		return move
+// encodeSubMoves packs up to four submoves into the [8]int move encoding,
+// padding unused slots with -1.
+func encodeSubMoves(subMoves []SubMove) [8]int {
+	move := [8]int{-1, -1, -1, -1, -1, -1, -1, -1}
+	for i, sm := range subMoves {
+		if i >= 4 {
+			break
+		}
+		move[2*i] = sm.From
+		move[2*i+1] = sm.To
 	}
+	return move
+}
 
-	// Split by spaces to get individual moves
-	parts := strings.Fields(moveStr)
-	idx := 0
+// parseMatSubMoves splits MAT move notation into individual checker hops.
+//
+// Each whitespace-separated token may be a simple move ("13/9"), a chained
+// move ("24/18/13", "bar/20*/15") or carry a repeat count ("6/4*(2)").
+// A "*" after a point marks a hit on that hop. Tokens that cannot be parsed
+// are reported as warnings instead of being silently dropped.
+func parseMatSubMoves(moveStr string) ([]SubMove, []string) {
+	lower := strings.ToLower(moveStr)
+	if strings.TrimSpace(moveStr) == "" || strings.Contains(lower, "can't move") ||
+		strings.Contains(lower, "cannot move") {
+		return nil, nil
+	}
 
-	for _, part := range parts {
-		if idx >= 8 {
-			break
-		}
+	var subMoves []SubMove
+	var warnings []string
 
+	for _, part := range strings.Fields(moveStr) {
 		// Check for multiplier (N) suffix, e.g., "18/16(2)"
 		multiplier := 1
 		cleanPart := part
 		if parenIdx := strings.LastIndex(part, "("); parenIdx >= 0 {
-			if closeIdx := strings.Index(part[parenIdx:], ")"); closeIdx >= 0 {
-				nStr := part[parenIdx+1 : parenIdx+closeIdx]
-				if n, err := strconv.Atoi(nStr); err == nil && n > 0 {
-					multiplier = n
-				}
-				// Remove the (N) suffix from the part for parsing
-				cleanPart = part[:parenIdx]
+			closeIdx := strings.Index(part[parenIdx:], ")")
+			if closeIdx < 0 {
+				warnings = append(warnings, fmt.Sprintf("unparseable move token %q", part))
+				continue
+			}
+			nStr := part[parenIdx+1 : parenIdx+closeIdx]
+			n, err := strconv.Atoi(nStr)
+			if err != nil || n <= 0 {
+				warnings = append(warnings, fmt.Sprintf("unparseable move token %q", part))
+				continue
 			}
+			multiplier = n
+			// Remove the (N) suffix from the part for parsing
+			cleanPart = part[:parenIdx]
 		}
 
-		// Parse "from/to" format
-		moveparts := strings.Split(cleanPart, "/")
-		if len(moveparts) != 2 {
+		// Parse "from/to" or chained "from/via/.../to" format
+		points := strings.Split(cleanPart, "/")
+		if len(points) < 2 {
+			warnings = append(warnings, fmt.Sprintf("unparseable move token %q", part))
 			continue
 		}
 
-		from := parseMatPoint(moveparts[0])
-		to := parseMatPoint(moveparts[1])
+		hops := make([]SubMove, 0, len(points)-1)
+		valid := true
+		for i := 0; i+1 < len(points); i++ {
+			from := parseMatPoint(points[i])
+			to := parseMatPoint(points[i+1])
+			// Off is only valid as a destination, bar only as a source
+			if from < 0 || from > 24 || to < -1 || to == 24 {
+				valid = false
+				break
+			}
+			hops = append(hops, SubMove{
+				From: from,
+				To:   to,
+				Hit:  strings.HasSuffix(strings.TrimSpace(points[i+1]), "*"),
+			})
+		}
+		if !valid {
+			warnings = append(warnings, fmt.Sprintf("unparseable move token %q", part))
+			continue
+		}
 
-		if from >= 0 && to >= -1 {
-			for r := 0; r < multiplier && idx < 8; r++ {
-				move[idx] = from
-				move[idx+1] = to
-				idx += 2
+		for r := 0; r < multiplier; r++ {
+			for _, hop := range hops {
+				// Only the first checker can hit: later ones land on the
+				// checker that did the hitting.
+				if r > 0 {
+					hop.Hit = false
+				}
+				subMoves = append(subMoves, hop)
 			}
 		}
 	}
 
-	return move
+	if len(subMoves) > 4 {
+		warnings = append(warnings, fmt.Sprintf("move %q has %d submoves, only the first 4 are encoded", moveStr, len(subMoves)))
+	}
+
+	return subMoves, warnings
 }
 
 // parseMatPoint converts a MAT point notation to internal format
@@ -177,6 +177,89 @@ func TestParseMATBasic(t *testing.T) {
 	}
 }
 
+func TestParseMATHeaderTags(t *testing.T) {
+	matContent := `; [Site "XG Mobile"]
+; [Match ID "12345"]
+; [Player 1 "Alice Smith"]
+; [Player 2 "Bob"]
+; [Player 1 Elo "1845.20/312"]
+; [EventDate "2024.03.15"]
+; [EventTime "21.30"]
+; [Variation "Backgammon"]
+; [Jacoby "On"]
+; [Beaver "On"]
+; [CubeLimit "64"]
+; [Unrated]
+
+ 5 point match
+
+ Game 1
+ Alice Smith : 0                   Bob, 1650.5 : 0
+  1)                             41: 13/9 24/23
+                                  Wins 1 point
+`
+
+	match, err := ParseMAT(strings.NewReader(matContent))
+	if err != nil {
+		t.Fatalf("Failed to parse MAT: %v", err)
+	}
+
+	md := match.Metadata
+	if md.Player1 != "Alice Smith" || md.Player2 != "Bob" {
+		t.Errorf("Players = %q vs %q, want Alice Smith vs Bob", md.Player1, md.Player2)
+	}
+	if md.Rating1 != "1845.20/312" {
+		t.Errorf("Rating1 = %q, want 1845.20/312", md.Rating1)
+	}
+	if md.Rating2 != "1650.5" {
+		t.Errorf("Rating2 = %q, want rating from score line 1650.5", md.Rating2)
+	}
+	if md.Place != "XG Mobile" || md.Date != "2024-03-15" || md.EventTime != "21.30" {
+		t.Errorf("Place/Date/EventTime = %q/%q/%q", md.Place, md.Date, md.EventTime)
+	}
+	if !md.Beaver || !md.Unrated || md.CubeLimit != 64 {
+		t.Errorf("Beaver=%v Unrated=%v CubeLimit=%d", md.Beaver, md.Unrated, md.CubeLimit)
+	}
+	if md.Tags["Match ID"] != "12345" {
+		t.Errorf("Tags = %v, want Match ID preserved", md.Tags)
+	}
+
+	game := match.Games[0]
+	if game.Jacoby {
+		t.Error("Jacoby tag should not apply to match play")
+	}
+	if game.Variation != "Standard" {
+		t.Errorf("Variation = %q, want Standard", game.Variation)
+	}
+}
+
+func TestParseMATJacobyMoney(t *testing.T) {
+	for _, tt := range []struct {
+		header string
+		want   bool
+	}{
+		{"; [Jacoby \"On\"]\n", true},
+		{"; [Jacoby \"Off\"]\n", false},
+		{"", true},
+	} {
+		matContent := tt.header + `
+ 0 point match
+
+ Game 1
+ alice : 0                   bob : 0
+  1)                             41: 13/9 24/23
+                                  Wins 1 point
+`
+		match, err := ParseMAT(strings.NewReader(matContent))
+		if err != nil {
+			t.Fatalf("ParseMAT failed: %v", err)
+		}
+		if got := match.Games[0].Jacoby; got != tt.want {
+			t.Errorf("Header %q: Jacoby = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
 func TestParseMatMove(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -234,6 +317,34 @@ func TestParseMatMove(t *testing.T) {
 			input:    "5/0 5/0 5/0 5/0",
 			expected: [8]int{4, -1, 4, -1, 4, -1, 4, -1}, // Four bearoffs
 		},
+		{
+			input:    "24/18/13",
+			expected: [8]int{23, 17, 17, 12, -1, -1, -1, -1}, // Chained move
+		},
+		{
+			input:    "13/7*/5",
+			expected: [8]int{12, 6, 6, 4, -1, -1, -1, -1}, // Chained move with hit
+		},
+		{
+			input:    "bar/20*/15",
+			expected: [8]int{24, 19, 19, 14, -1, -1, -1, -1}, // Chained bar entry
+		},
+		{
+			input:    "6/4*(2)",
+			expected: [8]int{5, 3, 5, 3, -1, -1, -1, -1}, // Hit with multiplier
+		},
+		{
+			input:    "Bar/22 6/Off",
+			expected: [8]int{24, 21, 5, -1, -1, -1, -1, -1}, // Capitalised bar/off
+		},
+		{
+			input:    "24/20/16/12/8",
+			expected: [8]int{23, 19, 19, 15, 15, 11, 11, 7}, // Doubles played as one chain
+		},
+		{
+			input:    "13/9 garbage",
+			expected: [8]int{12, 8, -1, -1, -1, -1, -1, -1}, // Unparseable token skipped
+		},
 	}
 
 	for _, tt := range tests {
@@ -246,6 +357,99 @@ func TestParseMatMove(t *testing.T) {
 	}
 }
 
+func TestParseMatSubMoves(t *testing.T) {
+	tests := []struct {
+		input        string
+		expected     []SubMove
+		wantWarnings int
+	}{
+		{
+			input: "13/7*/5",
+			expected: []SubMove{
+				{From: 12, To: 6, Hit: true},
+				{From: 6, To: 4},
+			},
+		},
+		{
+			input: "bar/20*/15*",
+			expected: []SubMove{
+				{From: 24, To: 19, Hit: true},
+				{From: 19, To: 14, Hit: true},
+			},
+		},
+		{
+			input: "6/4*(2)",
+			expected: []SubMove{
+				{From: 5, To: 3, Hit: true},
+				{From: 5, To: 3},
+			},
+		},
+		{
+			input: "25/20 0/5 8/x 6/3(z)",
+			expected: []SubMove{
+				{From: 24, To: 19},
+			},
+			wantWarnings: 3,
+		},
+		{
+			input:        "24/20(2) 13/9(3)",
+			expected:     []SubMove{{23, 19, false}, {23, 19, false}, {12, 8, false}, {12, 8, false}, {12, 8, false}},
+			wantWarnings: 1, // More than 4 submoves
+		},
+		{
+			input: "Can't move",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			subMoves, warnings := parseMatSubMoves(tt.input)
+			if len(subMoves) != len(tt.expected) {
+				t.Fatalf("parseMatSubMoves(%q) = %v, want %v", tt.input, subMoves, tt.expected)
+			}
+			for i := range subMoves {
+				if subMoves[i] != tt.expected[i] {
+					t.Errorf("parseMatSubMoves(%q)[%d] = %v, want %v", tt.input, i, subMoves[i], tt.expected[i])
+				}
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("parseMatSubMoves(%q) warnings = %v, want %d", tt.input, warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestParseMATMoveWarnings(t *testing.T) {
+	matContent := ` 3 point match
+
+ Game 1
+ Player1 : 0                   Player2 : 0
+  1)                             41: 13/9 24/23/x
+  2) 31: 8/5/4                   21: 6/4* 13/12
+                                  Wins 1 point
+`
+
+	match, err := ParseMAT(strings.NewReader(matContent))
+	if err != nil {
+		t.Fatalf("Failed to parse MAT: %v", err)
+	}
+
+	if len(match.Warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %v", match.Warnings)
+	}
+	if !strings.Contains(match.Warnings[0], "24/23/x") {
+		t.Errorf("Warning should mention the bad token, got %q", match.Warnings[0])
+	}
+
+	move := match.Games[0].Moves[1]
+	if len(move.SubMoves) != 2 || move.SubMoves[0].From != 7 || move.SubMoves[1].To != 3 {
+		t.Errorf("Chained move 8/5/4 parsed as %v", move.SubMoves)
+	}
+	if hit := match.Games[0].Moves[2].SubMoves[0].Hit; !hit {
+		t.Error("Expected hit marker on 6/4*")
+	}
+}
+
 func TestParseMatPoint(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -307,3 +511,233 @@ func TestSplitMoveLine(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectMATDialect(t *testing.T) {
+	tests := []struct {
+		name     string
+		comments []string
+		want     MATDialect
+	}{
+		{"no header", nil, MATDialectJellyfish},
+		{"gnuBG export", []string{`[EventDate "2025.11.08"]`}, MATDialectJellyfish},
+		{"XG transcriber", []string{`[Player 1 "Alice"]`, `[Transcriber "eXtremeGammon"]`}, MATDialectXG},
+		{"BGBlitz player tags", []string{`[Player 1 "Alice"]`, `[Player 2 "Bob"]`, `[Site "BGBlitz"]`}, MATDialectJellyfish},
+		{"GridGammon site", []string{`[Site "GridGammon"]`}, MATDialectGridGammon},
+		{"GamesGrid site", []string{`[Site "GamesGrid"]`}, MATDialectGridGammon},
+		{"FIBS site", []string{`[Site "FIBS"]`}, MATDialectFIBS},
+		{"BGOnline site", []string{`[Site "BGOnline.org"]`}, MATDialectBGOnline},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectMATDialect(tt.comments); got != tt.want {
+				t.Errorf("detectMATDialect(%v) = %q, want %q", tt.comments, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMATDialectSplit(t *testing.T) {
+	// Fixed columns measured from the layout handle a long left move that is
+	// separated from the right column by a single space.
+	layout := []string{
+		"                             41: 13/9 24/23",
+		" 31: 6/5 8/5                 41: 6/5 9/5",
+	}
+	rules := newMATDialectRules(MATDialectJellyfish, layout)
+	if rules.rightColumn != 29 || rules.beaversRe != nil {
+		t.Errorf("Jellyfish rules: rightColumn = %d, beavers = %v", rules.rightColumn, rules.beaversRe != nil)
+	}
+	got := rules.split(" 66: 24/18 24/18 13/7* 13/7 55: 25/20 25/20 20/15 6/1")
+	want := [2]string{"66: 24/18 24/18 13/7* 13/7", "55: 25/20 25/20 20/15 6/1"}
+	if got != want {
+		t.Errorf("split() = %q, want %q", got, want)
+	}
+	got = rules.split("  Doubles => 2                Takes")
+	want = [2]string{"Doubles => 2", "Takes"}
+	if got != want {
+		t.Errorf("split() = %q, want %q", got, want)
+	}
+
+	// FIBS pads columns by name length, so no fixed column is kept
+	rules = newMATDialectRules(MATDialectFIBS, layout)
+	if rules.rightColumn != 0 {
+		t.Errorf("FIBS rules: rightColumn = %d, want 0", rules.rightColumn)
+	}
+	got = rules.split(" 31: 6/5 8/5                 41: 6/5 9/5")
+	want = [2]string{"31: 6/5 8/5", "41: 6/5 9/5"}
+	if got != want {
+		t.Errorf("split() = %q, want %q", got, want)
+	}
+	if rules := newMATDialectRules(MATDialectXG, layout); rules.beaversRe == nil {
+		t.Error("XG rules do not accept beavers")
+	}
+
+	// Tab-separated layouts split on tabs
+	rules = newMATDialectRules(MATDialectJellyfish, []string{
+		"\t\t41: 13/9 24/23",
+		"\t31: 6/5 8/5\t41: 6/5 9/5",
+	})
+	if !rules.tabSeparated {
+		t.Fatal("Expected tab-separated layout")
+	}
+	got = rules.split("\t\t41: 13/9 24/23")
+	want = [2]string{"", "41: 13/9 24/23"}
+	if got != want {
+		t.Errorf("split() = %q, want %q", got, want)
+	}
+}
+
+func TestParseMATSiteDialects(t *testing.T) {
+	for _, tt := range []struct {
+		site string
+		want MATDialect
+	}{
+		{"GridGammon", MATDialectGridGammon},
+		{"BGOnline.org", MATDialectBGOnline},
+	} {
+		matContent := `; [Site "` + tt.site + `"]
+
+ 3 point match
+
+ Game 1
+ alice : 0                          bob : 0
+  1)                                41: 13/9 24/23
+  2) 31: 8/5 6/5                    Doubles => 2
+  3)  Drops
+                                    Wins 1 point
+`
+		match, err := ParseMAT(strings.NewReader(matContent))
+		if err != nil {
+			t.Fatalf("%s: ParseMAT failed: %v", tt.site, err)
+		}
+		if match.Dialect != tt.want {
+			t.Errorf("%s: Dialect = %q, want %q", tt.site, match.Dialect, tt.want)
+		}
+		// Jellyfish rules: fixed columns and no beavers
+		moves := match.Games[0].Moves
+		if len(moves) != 4 || moves[0].Player != 1 || moves[2].Type != MoveTypeDouble || moves[3].Type != MoveTypeDrop {
+			t.Errorf("%s: moves = %+v", tt.site, moves)
+		}
+	}
+}
+
+func TestParseMATDialectKeywords(t *testing.T) {
+	matContent := `; [Transcriber "eXtremeGammon"]
+; [Player 1 "Alice"]
+; [Player 2 "Bob"]
+
+ 3 point match
+
+ Game 1
+ Alice : 0                          Bob : 0
+  1)                                41: 13/9 24/23
+  2) 43: Can't move                 Doubles => 2
+  3)  Beavers => 4                  Takes
+  4)                                62: 24/18 13/11
+  5)  Doubles => 8                  Drops
+      Wins 4 points
+`
+
+	match, err := ParseMAT(strings.NewReader(matContent))
+	if err != nil {
+		t.Fatalf("Failed to parse MAT: %v", err)
+	}
+	if match.Dialect != MATDialectXG {
+		t.Errorf("Dialect = %q, want %q", match.Dialect, MATDialectXG)
+	}
+
+	var types []MoveType
+	for _, mr := range match.Games[0].Moves {
+		types = append(types, mr.Type)
+	}
+	wantTypes := []MoveType{
+		MoveTypeNormal, MoveTypeNormal, MoveTypeDouble,
+		MoveTypeDouble, MoveTypeTake,
+		MoveTypeNormal, MoveTypeDouble, MoveTypeDrop,
+	}
+	if len(types) != len(wantTypes) {
+		t.Fatalf("Move types = %v, want %v", types, wantTypes)
+	}
+	for i := range types {
+		if types[i] != wantTypes[i] {
+			t.Errorf("Move %d type = %s, want %s", i, types[i], wantTypes[i])
+		}
+	}
+	if beaver := match.Games[0].Moves[3]; beaver.Player != 0 || beaver.CubeValue != 4 {
+		t.Errorf("Beaver = %+v, want player 0 redoubling to 4", beaver)
+	}
+	if take := match.Games[0].Moves[4]; take.Player != 1 || take.Cube.Value != 1 {
+		t.Errorf("Take = %+v, want player 2 accepting the beaver", take)
+	}
+	if cube := match.Games[0].Moves[6].Cube; cube.Value != 4 || cube.Owner != 0 {
+		t.Errorf("Cube after the beaver = %+v, want 4 owned by player 1", cube)
+	}
+	if len(match.Warnings) > 0 {
+		t.Errorf("Unexpected warnings: %v", match.Warnings)
+	}
+	if issues := match.Validate(); len(issues) > 0 {
+		t.Errorf("Unexpected validation issues: %v", issues)
+	}
+	if cantMove := match.Games[0].Moves[1]; cantMove.Move[0] != -1 {
+		t.Errorf("Can't move encoded as %v", cantMove.Move)
+	}
+}
+
+func TestParseAllMAT(t *testing.T) {
+	matContent := `; [Event "Club night"]
+
+ 3 point match
+
+ Game 1
+ alice : 0                   bob : 0
+  1)                             41: 13/9 24/23
+  2) 31: 8/5 6/5                  Doubles => 2
+  3)  Drops
+                                  Wins 1 point
+
+ Game 2
+ alice : 0                   bob : 1
+  1) 31: 8/5 6/5                 Doubles => 2
+  2)  Drops
+                                  Wins 1 point
+
+; [Event "Club night, round 2"]
+
+ 1 point match
+
+ Game 1
+ carol : 0                   dave : 0
+  1) 31: 8/5 6/5                 41: 13/9 24/23
+      Wins 1 point
+`
+
+	matches, err := ParseAllMAT(strings.NewReader(matContent))
+	if err != nil {
+		t.Fatalf("ParseAllMAT failed: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("Got %d matches, want 2", len(matches))
+	}
+
+	if len(matches[0].Games) != 2 || matches[0].Metadata.MatchLength != 3 || matches[0].Metadata.Event != "Club night" {
+		t.Errorf("Match 1 = %d games, length %d, event %q",
+			len(matches[0].Games), matches[0].Metadata.MatchLength, matches[0].Metadata.Event)
+	}
+	if len(matches[1].Games) != 1 || matches[1].Metadata.MatchLength != 1 || matches[1].Metadata.Player1 != "carol" {
+		t.Errorf("Match 2 = %d games, length %d, player %q",
+			len(matches[1].Games), matches[1].Metadata.MatchLength, matches[1].Metadata.Player1)
+	}
+	if matches[1].Metadata.Event != "Club night, round 2" {
+		t.Errorf("Match 2 event = %q, header comments should move to the next match", matches[1].Metadata.Event)
+	}
+
+	// ParseMAT returns only the first match
+	match, err := ParseMAT(strings.NewReader(matContent))
+	if err != nil {
+		t.Fatalf("ParseMAT failed: %v", err)
+	}
+	if len(match.Games) != 2 {
+		t.Errorf("ParseMAT returned %d games, want 2", len(match.Games))
+	}
+}
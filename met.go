@@ -0,0 +1,300 @@
+package gnubgparser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MatchEquityTable holds the match winning chances (MWC) used to convert
+// gnuBG's cubeful match play equities into equivalent money game equity
+// (EMG) and back. gnuBG stores cubeful cube analysis as MWC in match play;
+// converting it requires the MWC of winning or losing the cube value at the
+// current score. Set Match.MET to use another table than the default.
+type MatchEquityTable struct {
+	Name string `json:"name"`
+	// PreCrawford[i][j] is the MWC of a player needing i+1 points against
+	// an opponent needing j+1 before the Crawford game has been played;
+	// the 1-away rows and columns hold the Crawford game
+	PreCrawford [][]float64 `json:"pre_crawford"`
+	// PostCrawford[i] is the MWC of a player needing i+1 points against an
+	// opponent needing one, after the Crawford game
+	PostCrawford []float64 `json:"post_crawford"`
+}
+
+// Kazaross-XG2 table, gnuBG's default, in percent for the player needing
+// the row's points against the column's. Scores beyond a table are
+// extended with metGammonRate.
+var kazarossXG2PreCrawford = [15][15]float64{
+	{50.0, 68.1, 75.0, 81.4, 84.2, 88.7, 90.7, 93.2, 94.4, 95.9, 96.7, 97.6, 98.1, 98.6, 98.9},
+	{31.9, 50.0, 59.9, 66.9, 74.4, 79.9, 84.2, 87.5, 90.2, 92.4, 94.1, 95.4, 96.5, 97.3, 97.9},
+	{25.0, 40.1, 50.0, 57.4, 64.7, 70.7, 75.9, 80.3, 83.9, 87.0, 89.4, 91.5, 93.2, 94.6, 95.7},
+	{18.6, 33.1, 42.6, 50.0, 57.1, 63.4, 69.1, 74.0, 78.3, 82.0, 85.1, 87.8, 90.0, 91.9, 93.4},
+	{15.8, 25.6, 35.3, 42.9, 50.0, 56.5, 62.6, 68.0, 72.8, 77.0, 80.7, 83.9, 86.6, 88.9, 90.9},
+	{11.3, 20.1, 29.3, 36.6, 43.5, 50.0, 56.2, 61.8, 66.9, 71.6, 75.7, 79.4, 82.6, 85.4, 87.8},
+	{9.3, 15.8, 24.1, 30.9, 37.4, 43.8, 50.0, 55.7, 61.0, 65.9, 70.3, 74.3, 77.9, 81.1, 83.9},
+	{6.8, 12.5, 19.7, 26.0, 32.0, 38.2, 44.3, 50.0, 55.4, 60.4, 65.0, 69.2, 73.0, 76.5, 79.6},
+	{5.6, 9.8, 16.1, 21.7, 27.2, 33.1, 39.0, 44.6, 50.0, 55.1, 59.9, 64.3, 68.4, 72.1, 75.5},
+	{4.1, 7.6, 13.0, 18.0, 23.0, 28.4, 34.1, 39.6, 44.9, 50.0, 54.9, 59.4, 63.7, 67.6, 71.2},
+	{3.3, 5.9, 10.6, 14.9, 19.3, 24.3, 29.7, 35.0, 40.1, 45.1, 50.0, 54.6, 59.0, 63.0, 66.8},
+	{2.4, 4.6, 8.5, 12.2, 16.1, 20.6, 25.7, 30.8, 35.7, 40.6, 45.4, 50.0, 54.5, 58.6, 62.5},
+	{1.9, 3.5, 6.8, 10.0, 13.4, 17.4, 22.1, 27.0, 31.6, 36.3, 41.0, 45.5, 50.0, 54.2, 58.3},
+	{1.4, 2.7, 5.4, 8.1, 11.1, 14.6, 18.9, 23.5, 27.9, 32.4, 37.0, 41.4, 45.8, 50.0, 54.1},
+	{1.1, 2.1, 4.3, 6.6, 9.1, 12.2, 16.1, 20.4, 24.5, 28.8, 33.2, 37.5, 41.7, 45.9, 50.0},
+}
+
+var kazarossXG2PostCrawford = [15]float64{
+	50.0, 48.8, 32.2, 31.0, 19.0, 18.5, 11.4, 11.1, 6.8, 6.6, 4.0, 3.9, 2.4, 2.3, 1.4,
+}
+
+// Share of games ending in a gammon, used beyond the table
+const metGammonRate = 0.26
+
+// defaultMET is the table used when a match does not set one
+var defaultMET = newKazarossXG2()
+
+// DefaultMET returns gnuBG's default match equity table, Kazaross-XG2
+func DefaultMET() *MatchEquityTable {
+	return defaultMET
+}
+
+// newKazarossXG2 converts the Kazaross-XG2 percentages into a table
+func newKazarossXG2() *MatchEquityTable {
+	t := &MatchEquityTable{Name: "Kazaross-XG2"}
+	for _, row := range kazarossXG2PreCrawford {
+		mwc := make([]float64, len(row))
+		for j, v := range row {
+			mwc[j] = v / 100
+		}
+		t.PreCrawford = append(t.PreCrawford, mwc)
+	}
+	for _, v := range kazarossXG2PostCrawford {
+		t.PostCrawford = append(t.PostCrawford, v/100)
+	}
+	return t
+}
+
+// ReadMETFile reads a match equity table file in gnuBG's XML format, as
+// found in its met directory
+func ReadMETFile(filename string) (*MatchEquityTable, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return ReadMET(file)
+}
+
+// metXMLTable is a pre- or post-Crawford table of a gnuBG MET file
+type metXMLTable struct {
+	Type string `xml:"type,attr"`
+	Rows []struct {
+		ME []string `xml:"me"`
+	} `xml:"row"`
+}
+
+// ReadMET reads a match equity table in gnuBG's XML format. Only explicit
+// tables are supported; the post-Crawford table of the first player is
+// used for both players.
+func ReadMET(r io.Reader) (*MatchEquityTable, error) {
+	var doc struct {
+		Name string        `xml:"info>name"`
+		Pre  metXMLTable   `xml:"pre-crawford-table"`
+		Post []metXMLTable `xml:"post-crawford-table"`
+	}
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = latin1Reader
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid MET file: %w", err)
+	}
+
+	t := &MatchEquityTable{Name: strings.TrimSpace(doc.Name)}
+	for _, table := range append([]metXMLTable{doc.Pre}, doc.Post...) {
+		if table.Type != "" && table.Type != "explicit" {
+			return nil, fmt.Errorf("invalid MET file: %s tables are not supported", table.Type)
+		}
+	}
+	for i, row := range doc.Pre.Rows {
+		mwc, err := parseMETRow(row.ME)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MET file: pre-Crawford row %d: %w", i+1, err)
+		}
+		t.PreCrawford = append(t.PreCrawford, mwc)
+	}
+	if len(doc.Post) > 0 && len(doc.Post[0].Rows) > 0 {
+		mwc, err := parseMETRow(doc.Post[0].Rows[0].ME)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MET file: post-Crawford table: %w", err)
+		}
+		t.PostCrawford = mwc
+	}
+	if len(t.PreCrawford) == 0 || len(t.PostCrawford) == 0 {
+		return nil, fmt.Errorf("invalid MET file: missing pre- or post-Crawford table")
+	}
+	return t, nil
+}
+
+// latin1Reader decodes the ISO-8859-1 encoding gnuBG declares in its MET
+// files
+func latin1Reader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "us-ascii":
+	default:
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return strings.NewReader(string(runes)), nil
+}
+
+// parseMETRow parses the equities of one table row
+func parseMETRow(values []string) ([]float64, error) {
+	mwc := make([]float64, len(values))
+	for i, v := range values {
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, err
+		}
+		mwc[i] = f
+	}
+	return mwc, nil
+}
+
+// Equity returns the match winning chance of a player needing away points
+// against an opponent needing oppAway points. crawfordDone reports whether
+// the Crawford game has already been played. A nil table is the default.
+func (t *MatchEquityTable) Equity(away, oppAway int, crawfordDone bool) float64 {
+	if t == nil {
+		t = defaultMET
+	}
+	switch {
+	case away <= 0:
+		return 1
+	case oppAway <= 0:
+		return 0
+	case away == 1 && oppAway == 1:
+		return 0.5
+	case oppAway == 1 && crawfordDone:
+		return t.postCrawford(away)
+	case away == 1 && crawfordDone:
+		return 1 - t.postCrawford(oppAway)
+	}
+	return t.preCrawford(away, oppAway)
+}
+
+// postCrawford returns the MWC of a player needing away points against an
+// opponent needing one after the Crawford game. Beyond the table the
+// trailer doubles at once, so only wins and gammons at doubled stakes
+// matter.
+func (t *MatchEquityTable) postCrawford(away int) float64 {
+	post := append([]float64{1}, t.PostCrawford...)
+	for n := len(post); n <= away; n++ {
+		g := metGammonRate
+		post = append(post, 0.5*((1-g)*post[n-2]+g*post[max(n-4, 0)]))
+	}
+	return post[max(away, 0)]
+}
+
+// preCrawford returns the MWC before the Crawford game has been played
+func (t *MatchEquityTable) preCrawford(away, oppAway int) float64 {
+	if t.inTable(away, oppAway) {
+		return t.PreCrawford[away-1][oppAway-1]
+	}
+
+	// Beyond the table: the Crawford game from the post-Crawford equities,
+	// other scores from the single games and gammons played to reach them
+	g := metGammonRate
+	n := max(away, oppAway)
+	mwc := make([][]float64, n+1)
+	at := func(i, j int) float64 {
+		switch {
+		case i <= 0:
+			return 1
+		case j <= 0:
+			return 0
+		}
+		return mwc[i][j]
+	}
+	crawford := func(trailer int) float64 {
+		return 0.5 * ((1-g)*t.postCrawford(trailer-1) + g*t.postCrawford(trailer-2))
+	}
+	for i := 1; i <= n; i++ {
+		mwc[i] = make([]float64, n+1)
+		for j := 1; j <= n; j++ {
+			switch {
+			case t.inTable(i, j):
+				mwc[i][j] = t.PreCrawford[i-1][j-1]
+			case j == 1:
+				mwc[i][j] = crawford(i)
+			case i == 1:
+				mwc[i][j] = 1 - crawford(j)
+			default:
+				mwc[i][j] = 0.5*((1-g)*at(i-1, j)+g*at(i-2, j)) + 0.5*((1-g)*at(i, j-1)+g*at(i, j-2))
+			}
+		}
+	}
+	return mwc[away][oppAway]
+}
+
+// inTable reports whether the pre-Crawford table holds a score
+func (t *MatchEquityTable) inTable(away, oppAway int) bool {
+	return away <= len(t.PreCrawford) && oppAway <= len(t.PreCrawford[away-1])
+}
+
+// MatchEquity returns the match winning chance of a player needing away
+// points against an opponent needing oppAway points in the default table.
+// crawfordDone reports whether the Crawford game has already been played.
+func MatchEquity(away, oppAway int, crawfordDone bool) float64 {
+	return defaultMET.Equity(away, oppAway, crawfordDone)
+}
+
+// equityAt returns player's MWC at a score
+func (t *MatchEquityTable) equityAt(score [2]int, matchLength int, crawfordDone bool, player int) float64 {
+	return t.Equity(matchLength-score[player], matchLength-score[1-player], crawfordDone)
+}
+
+// mwcToEMG converts a match winning chance of player in game, with the
+// given cube value, into equivalent money game equity normalized to the
+// cube: winning the cube value is +1 and losing it is -1.
+func (t *MatchEquityTable) mwcToEMG(mwc float64, game *Game, matchLength, player, cube int) float64 {
+	win, lose := t.cubeOutcomes(game, matchLength, player, cube)
+	if win == lose {
+		return 0
+	}
+	return (2*mwc - (win + lose)) / (win - lose)
+}
+
+// cubeOutcomes returns player's MWC after winning and after losing the
+// cube value in game
+func (t *MatchEquityTable) cubeOutcomes(game *Game, matchLength, player, cube int) (float64, float64) {
+	away := matchLength - game.Score[player]
+	oppAway := matchLength - game.Score[1-player]
+	// With a player 1-away, the Crawford game is this one or already played
+	crawfordDone := game.CrawfordGame || away == 1 || oppAway == 1
+
+	return t.Equity(away-cube, oppAway, crawfordDone), t.Equity(away, oppAway-cube, crawfordDone)
+}
+
+// emgToMWC converts an equity in EMG for player, normalized to the cube,
+// into match winning chances
+func (t *MatchEquityTable) emgToMWC(emg float64, game *Game, matchLength, player, cube int) float64 {
+	win, lose := t.cubeOutcomes(game, matchLength, player, cube)
+	return (emg*(win-lose) + win + lose) / 2
+}
+
+// emgDeltaToMWC converts an equity change in EMG for player, normalized to
+// the cube, into the matching change in match winning chances
+func (t *MatchEquityTable) emgDeltaToMWC(delta float64, game *Game, matchLength, player, cube int) float64 {
+	win, lose := t.cubeOutcomes(game, matchLength, player, cube)
+	return delta * (win - lose) / 2
+}
@@ -63,6 +63,41 @@ func ParseSGF(r io.Reader) (*Match, error) {
 	return match, nil
 }
 
+// ParseAllSGFFile parses an SGF file that may hold several matches
+func ParseAllSGFFile(filename string) ([]*Match, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return ParseAllSGF(file)
+}
+
+// ParseAllSGF parses SGF data holding one or more matches and returns one
+// Match per match. Unlike ParseSGF, which merges every game tree into a
+// single Match, a new match is started whenever the players (PW/PB) or
+// the match length change, or the game number does not increase.
+func ParseAllSGF(r io.Reader) ([]*Match, error) {
+	parser := NewSGFParser(r)
+
+	nodes, err := parser.parseGameTree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SGF: %w", err)
+	}
+
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("empty SGF file")
+	}
+
+	matches, err := convertNodesToMatches(nodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert SGF to match: %w", err)
+	}
+
+	return matches, nil
+}
+
 // parseGameTree parses an SGF game tree
 func (p *SGFParser) parseGameTree() ([]*SGFNode, error) {
 	var games []*SGFNode
@@ -346,11 +346,62 @@ func TestCannotMoveWithCubeAnalysis(t *testing.T) {
 		t.Errorf("Analysis move string = %q, want %q", opt.MoveString, "Cannot Move")
 	}
 
-	// Verify win rates from DA property
-	if opt.Player1WinRate < 0.21 || opt.Player1WinRate > 0.22 {
-		t.Errorf("Player1WinRate = %f, expected ~0.216329", opt.Player1WinRate)
+	// Verify win rates from DA property: B is on roll, so the on-roll
+	// probabilities go to Player2
+	if opt.OnRollWin < 0.21 || opt.OnRollWin > 0.22 {
+		t.Errorf("OnRollWin = %f, expected ~0.216329", opt.OnRollWin)
+	}
+	if opt.Player2WinRate != opt.OnRollWin || opt.Player1WinRate != opt.OpponentWin {
+		t.Errorf("Player2WinRate/Player1WinRate = %f/%f, want %f/%f",
+			opt.Player2WinRate, opt.Player1WinRate, opt.OnRollWin, opt.OpponentWin)
 	}
 	if opt.Equity < -0.82 || opt.Equity > -0.80 {
 		t.Errorf("Equity = %f, expected ~-0.811862", opt.Equity)
 	}
 }
+
+func TestParseAllSGF(t *testing.T) {
+	sgf := `(;FF[4]GM[6]MI[length:3][game:0][ws:0][bs:0]PW[alice]PB[bob]RE[W+2]
+;W[31fehe])
+(;FF[4]GM[6]MI[length:3][game:1][ws:2][bs:0]PW[alice]PB[bob]RE[W+1]
+;B[41lpab])
+(;FF[4]GM[6]MI[length:5][game:0][ws:0][bs:0]PW[carol]PB[dave]RE[B+1]
+;W[31fehe])
+(;FF[4]GM[6]MI[length:5][game:0][ws:0][bs:0]PW[carol]PB[dave]RE[B+1]
+;W[31fehe])
+`
+	matches, err := ParseAllSGF(strings.NewReader(sgf))
+	if err != nil {
+		t.Fatalf("ParseAllSGF failed: %v", err)
+	}
+
+	// Players change after game 2, and the game number resets for game 4
+	if len(matches) != 3 {
+		t.Fatalf("Got %d matches, want 3", len(matches))
+	}
+	if len(matches[0].Games) != 2 || matches[0].Metadata.Player1 != "alice" || matches[0].Metadata.MatchLength != 3 {
+		t.Errorf("Match 1 = %d games, %s, length %d", len(matches[0].Games), matches[0].Metadata.Player1, matches[0].Metadata.MatchLength)
+	}
+	if len(matches[1].Games) != 1 || matches[1].Metadata.Player1 != "carol" || matches[1].Metadata.MatchLength != 5 {
+		t.Errorf("Match 2 = %d games, %s, length %d", len(matches[1].Games), matches[1].Metadata.Player1, matches[1].Metadata.MatchLength)
+	}
+
+	// ParseSGF still merges every game tree
+	match, err := ParseSGF(strings.NewReader(sgf))
+	if err != nil {
+		t.Fatalf("ParseSGF failed: %v", err)
+	}
+	if len(match.Games) != 4 {
+		t.Errorf("ParseSGF returned %d games, want 4", len(match.Games))
+	}
+}
+
+func TestParseAllSGFFile(t *testing.T) {
+	matches, err := ParseAllSGFFile("test/charlot1-charlot2_7p_2025-11-08-2305.sgf")
+	if err != nil {
+		t.Fatalf("ParseAllSGFFile failed: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Errorf("Got %d matches, want 1", len(matches))
+	}
+}
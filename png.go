@@ -0,0 +1,229 @@
+package gnubgparser
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"unicode"
+)
+
+// RenderPNG writes the position as a PNG board diagram
+func (p *Position) RenderPNG(w io.Writer, opts DiagramOptions) error {
+	return png.Encode(w, p.RenderImage(opts))
+}
+
+// RenderImage draws the position as an image, the diagram of RenderPNG
+func (p *Position) RenderImage(opts DiagramOptions) *image.RGBA {
+	return layoutDiagram(p, opts).raster()
+}
+
+// raster draws the diagram into an image
+func (d *diagram) raster() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, int(d.width), int(d.height)))
+	fillRect(img, 0, 0, d.width, d.height, d.background)
+
+	for _, s := range d.shapes {
+		switch s.kind {
+		case shapeRect:
+			a, b := s.pts[0], s.pts[1]
+			fillRect(img, a.X, a.Y, b.X, b.Y, s.fill)
+			if s.stroke.A > 0 {
+				fillRect(img, a.X, a.Y, b.X, a.Y+1, s.stroke)
+				fillRect(img, a.X, b.Y-1, b.X, b.Y, s.stroke)
+				fillRect(img, a.X, a.Y, a.X+1, b.Y, s.stroke)
+				fillRect(img, b.X-1, a.Y, b.X, b.Y, s.stroke)
+			}
+		case shapePolygon:
+			fillPolygon(img, s.pts, s.fill)
+		case shapeCircle:
+			c := s.pts[0]
+			if s.stroke.A > 0 {
+				fillCircle(img, c, s.size, s.stroke)
+				fillCircle(img, c, s.size-math.Max(1, s.size/12), s.fill)
+			} else {
+				fillCircle(img, c, s.size, s.fill)
+			}
+		case shapeLine:
+			fillLine(img, s.pts[0], s.pts[1], s.size, s.fill)
+		case shapeText:
+			drawText(img, s.pts[0], s.size, s.text, s.anchor, s.fill)
+		}
+	}
+	return img
+}
+
+// blend paints a pixel with colour c at the given coverage
+func blend(img *image.RGBA, x, y int, c color.RGBA, coverage float64) {
+	if !(image.Point{x, y}.In(img.Rect)) || coverage <= 0 {
+		return
+	}
+	if coverage > 1 {
+		coverage = 1
+	}
+	a := coverage * float64(c.A) / 0xff
+	i := img.PixOffset(x, y)
+	px := img.Pix[i : i+4 : i+4]
+	mix := func(dst uint8, src uint8) uint8 {
+		return uint8(math.Round(float64(src)*a + float64(dst)*(1-a)))
+	}
+	px[0] = mix(px[0], c.R)
+	px[1] = mix(px[1], c.G)
+	px[2] = mix(px[2], c.B)
+	px[3] = mix(px[3], 0xff)
+}
+
+// fillRect fills the pixels whose centres lie in a rectangle
+func fillRect(img *image.RGBA, x0, y0, x1, y1 float64, c color.RGBA) {
+	for y := int(math.Round(y0)); y < int(math.Round(y1)); y++ {
+		for x := int(math.Round(x0)); x < int(math.Round(x1)); x++ {
+			blend(img, x, y, c, 1)
+		}
+	}
+}
+
+// fillPolygon fills the pixels whose centres lie in a polygon
+func fillPolygon(img *image.RGBA, pts []point, c color.RGBA) {
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, p := range pts {
+		minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+	}
+	for y := int(minY); y <= int(maxY); y++ {
+		for x := int(minX); x <= int(maxX); x++ {
+			px, py := float64(x)+0.5, float64(y)+0.5
+			inside := false
+			for i, j := 0, len(pts)-1; i < len(pts); j, i = i, i+1 {
+				a, b := pts[i], pts[j]
+				if (a.Y > py) != (b.Y > py) && px < (b.X-a.X)*(py-a.Y)/(b.Y-a.Y)+a.X {
+					inside = !inside
+				}
+			}
+			if inside {
+				blend(img, x, y, c, 1)
+			}
+		}
+	}
+}
+
+// fillCircle fills a circle with antialiased edges
+func fillCircle(img *image.RGBA, c point, r float64, col color.RGBA) {
+	for y := int(c.Y - r - 1); y <= int(c.Y+r+1); y++ {
+		for x := int(c.X - r - 1); x <= int(c.X+r+1); x++ {
+			d := math.Hypot(float64(x)+0.5-c.X, float64(y)+0.5-c.Y)
+			blend(img, x, y, col, r+0.5-d)
+		}
+	}
+}
+
+// fillLine draws a line of the given width with antialiased edges
+func fillLine(img *image.RGBA, a, b point, width float64, col color.RGBA) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	length2 := dx*dx + dy*dy
+	if length2 == 0 {
+		return
+	}
+	h := width / 2
+	for y := int(math.Min(a.Y, b.Y) - h - 1); y <= int(math.Max(a.Y, b.Y)+h+1); y++ {
+		for x := int(math.Min(a.X, b.X) - h - 1); x <= int(math.Max(a.X, b.X)+h+1); x++ {
+			px, py := float64(x)+0.5, float64(y)+0.5
+			t := math.Max(0, math.Min(1, ((px-a.X)*dx+(py-a.Y)*dy)/length2))
+			d := math.Hypot(px-(a.X+t*dx), py-(a.Y+t*dy))
+			blend(img, x, y, col, h+0.5-d)
+		}
+	}
+}
+
+// drawText draws text in the built-in bitmap font, scaled to about the
+// given height and centred vertically on the anchor point
+func drawText(img *image.RGBA, at point, size float64, s string, anchor textAnchor, c color.RGBA) {
+	scale := int(math.Round(size / 8))
+	if scale < 1 {
+		scale = 1
+	}
+	runes := []rune(s)
+	width := float64((len(runes)*6 - 1) * scale)
+	x0 := at.X
+	switch anchor {
+	case anchorMiddle:
+		x0 -= width / 2
+	case anchorEnd:
+		x0 -= width
+	}
+	left := int(math.Round(x0))
+	top := int(math.Round(at.Y - float64(7*scale)/2))
+
+	for k, r := range runes {
+		glyph, ok := fontGlyphs[unicode.ToUpper(r)]
+		if !ok && r != ' ' {
+			glyph = fontGlyphs['?']
+		}
+		for row, bits := range glyph {
+			for col := 0; col < 5; col++ {
+				if bits&(1<<(4-col)) == 0 {
+					continue
+				}
+				gx := left + (k*6+col)*scale
+				gy := top + row*scale
+				for sy := 0; sy < scale; sy++ {
+					for sx := 0; sx < scale; sx++ {
+						blend(img, gx+sx, gy+sy, c, 1)
+					}
+				}
+			}
+		}
+	}
+}
+
+// fontGlyphs is a 5x7 bitmap font for the text of raster diagrams; lower
+// case letters are drawn in upper case and other runes as "?"
+var fontGlyphs = map[rune][7]uint8{
+	'0': {0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110},
+	'1': {0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'2': {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111},
+	'3': {0b11111, 0b00010, 0b00100, 0b00010, 0b00001, 0b10001, 0b01110},
+	'4': {0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010},
+	'5': {0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110},
+	'6': {0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110},
+	'7': {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000},
+	'8': {0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110},
+	'9': {0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100},
+	'A': {0b01110, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001},
+	'B': {0b11110, 0b10001, 0b10001, 0b11110, 0b10001, 0b10001, 0b11110},
+	'C': {0b01110, 0b10001, 0b10000, 0b10000, 0b10000, 0b10001, 0b01110},
+	'D': {0b11100, 0b10010, 0b10001, 0b10001, 0b10001, 0b10010, 0b11100},
+	'E': {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b11111},
+	'F': {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b10000},
+	'G': {0b01110, 0b10001, 0b10000, 0b10111, 0b10001, 0b10001, 0b01111},
+	'H': {0b10001, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001},
+	'I': {0b01110, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'J': {0b00111, 0b00010, 0b00010, 0b00010, 0b00010, 0b10010, 0b01100},
+	'K': {0b10001, 0b10010, 0b10100, 0b11000, 0b10100, 0b10010, 0b10001},
+	'L': {0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b11111},
+	'M': {0b10001, 0b11011, 0b10101, 0b10101, 0b10001, 0b10001, 0b10001},
+	'N': {0b10001, 0b10001, 0b11001, 0b10101, 0b10011, 0b10001, 0b10001},
+	'O': {0b01110, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'P': {0b11110, 0b10001, 0b10001, 0b11110, 0b10000, 0b10000, 0b10000},
+	'Q': {0b01110, 0b10001, 0b10001, 0b10001, 0b10101, 0b10010, 0b01101},
+	'R': {0b11110, 0b10001, 0b10001, 0b11110, 0b10100, 0b10010, 0b10001},
+	'S': {0b01111, 0b10000, 0b10000, 0b01110, 0b00001, 0b00001, 0b11110},
+	'T': {0b11111, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100},
+	'U': {0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'V': {0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01010, 0b00100},
+	'W': {0b10001, 0b10001, 0b10001, 0b10101, 0b10101, 0b10101, 0b01010},
+	'X': {0b10001, 0b10001, 0b01010, 0b00100, 0b01010, 0b10001, 0b10001},
+	'Y': {0b10001, 0b10001, 0b10001, 0b01010, 0b00100, 0b00100, 0b00100},
+	'Z': {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b10000, 0b11111},
+	':': {0b00000, 0b01100, 0b01100, 0b00000, 0b01100, 0b01100, 0b00000},
+	'-': {0b00000, 0b00000, 0b00000, 0b11111, 0b00000, 0b00000, 0b00000},
+	'+': {0b00000, 0b00100, 0b00100, 0b11111, 0b00100, 0b00100, 0b00000},
+	'.': {0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b01100, 0b01100},
+	',': {0b00000, 0b00000, 0b00000, 0b00000, 0b01100, 0b00100, 0b01000},
+	'/': {0b00001, 0b00001, 0b00010, 0b00100, 0b01000, 0b10000, 0b10000},
+	'(': {0b00010, 0b00100, 0b01000, 0b01000, 0b01000, 0b00100, 0b00010},
+	')': {0b01000, 0b00100, 0b00010, 0b00010, 0b00010, 0b00100, 0b01000},
+	'*': {0b00000, 0b00100, 0b10101, 0b01110, 0b10101, 0b00100, 0b00000},
+	'_': {0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b11111},
+	'?': {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b00000, 0b00100},
+}
@@ -0,0 +1,151 @@
+package gnubgparser
+
+import "math"
+
+// RatingOptions holds the thresholds used to rate decisions and rolls
+// when the file does not provide SK or LU ratings. Skill thresholds are
+// equity losses (EMG, normalized to the cube); a decision losing more than
+// a threshold gets that rating. Luck thresholds are luck values: a roll
+// above LuckGood is Good, below LuckBad is Bad, and so on.
+type RatingOptions struct {
+	Doubtful float64
+	Bad      float64
+	VeryBad  float64
+
+	LuckVeryGood float64
+	LuckGood     float64
+	LuckBad      float64
+	LuckVeryBad  float64
+}
+
+// DefaultRatingOptions returns gnuBG's default thresholds
+func DefaultRatingOptions() RatingOptions {
+	return RatingOptions{
+		Doubtful:     0.04,
+		Bad:          0.08,
+		VeryBad:      0.16,
+		LuckVeryGood: 0.6,
+		LuckGood:     0.3,
+		LuckBad:      -0.3,
+		LuckVeryBad:  -0.6,
+	}
+}
+
+// SkillLevel rates an equity loss: "VeryBad", "Bad", "Doubtful" or "None"
+func (o RatingOptions) SkillLevel(loss float64) string {
+	switch {
+	case loss > o.VeryBad:
+		return "VeryBad"
+	case loss > o.Bad:
+		return "Bad"
+	case loss > o.Doubtful:
+		return "Doubtful"
+	}
+	return "None"
+}
+
+// LuckLevel rates a luck value: "VeryGood", "Good", "None", "Bad" or "VeryBad"
+func (o RatingOptions) LuckLevel(value float64) string {
+	switch {
+	case value > o.LuckVeryGood:
+		return "VeryGood"
+	case value > o.LuckGood:
+		return "Good"
+	case value < o.LuckVeryBad:
+		return "VeryBad"
+	case value < o.LuckBad:
+		return "Bad"
+	}
+	return "None"
+}
+
+// RateMatch derives skill and luck ratings for every analysed decision and
+// roll of the match. Ratings read from SK and LU properties are kept;
+// ratings derived by an earlier call are recomputed with opts, and so are
+// the game statistics counting them. ParseSGF already rates matches with
+// DefaultRatingOptions.
+func RateMatch(match *Match, opts RatingOptions) {
+	for i := range match.Games {
+		rateGame(&match.Games[i], match.Metadata.MatchLength, opts, match.MET)
+		match.Games[i].Statistics = computeGameStatistic(&match.Games[i], match.Metadata.MatchLength, match.MET)
+	}
+}
+
+// rateGame rates the decisions and rolls of one game
+func rateGame(game *Game, matchLength int, opts RatingOptions, met *MatchEquityTable) {
+	cubes, _, _ := replayCube(game)
+
+	for i := range game.Moves {
+		mr := &game.Moves[i]
+		cube := cubes[i].Value
+
+		if mr.Luck != nil && (mr.Luck.Rating == "" || mr.Luck.Derived) {
+			mr.Luck.Rating = opts.LuckLevel(mr.Luck.Value)
+			mr.Luck.Derived = true
+		}
+
+		switch mr.Type {
+		case MoveTypeNormal:
+			if mr.Analysis != nil && mr.Analysis.PlayedKnown() {
+				setDerivedSkill(&mr.Skill, mr.Analysis.EquityLoss, opts)
+			}
+			if nd, dt, dp, ok := cubeEquities(mr, game, matchLength, mr.Player, cube, met); ok {
+				best := math.Max(nd, math.Min(dt, dp))
+				setDerivedSkill(&mr.CubeSkill, best-nd, opts)
+			}
+
+		case MoveTypeDouble:
+			if nd, dt, dp, ok := cubeEquities(mr, game, matchLength, mr.Player, cube, met); ok {
+				best := math.Max(nd, math.Min(dt, dp))
+				setDerivedSkill(&mr.Skill, best-math.Min(dt, dp), opts)
+			}
+
+		case MoveTypeTake, MoveTypeDrop:
+			// The analysis is from the doubler's point of view: the
+			// opponent's best response minimizes the doubler's equity
+			if _, dt, dp, ok := cubeEquities(mr, game, matchLength, 1-mr.Player, cube, met); ok {
+				chosen := dt
+				if mr.Type == MoveTypeDrop {
+					chosen = dp
+				}
+				setDerivedSkill(&mr.Skill, chosen-math.Min(dt, dp), opts)
+			}
+		}
+	}
+}
+
+// cubeEquities returns the no double, double/take and double/pass
+// equities of a cube decision as EMG for player, who owns the decision.
+// In match play gnuBG stores the no double and double/take equities as
+// MWC; they are converted with the match equity table met (nil for the
+// default). Double/pass wins the cube value, already +1 EMG.
+func cubeEquities(mr *MoveRecord, game *Game, matchLength, player, cube int, met *MatchEquityTable) (float64, float64, float64, bool) {
+	ca := mr.CubeAnalysis
+	if ca == nil {
+		return 0, 0, 0, false
+	}
+
+	nd, dt, dp := ca.CubefulNoDouble, ca.CubefulDoubleTake, ca.CubefulDoublePass
+	if matchLength > 0 {
+		nd = met.mwcToEMG(nd, game, matchLength, player, cube)
+		dt = met.mwcToEMG(dt, game, matchLength, player, cube)
+	}
+	return nd, dt, dp, true
+}
+
+// setDerivedSkill stores a derived skill rating for an equity loss,
+// leaving ratings read from the file untouched
+func setDerivedSkill(skill **SkillRating, loss float64, opts RatingOptions) {
+	if *skill != nil && !(*skill).Derived {
+		return
+	}
+	if loss <= 1e-9 {
+		*skill = nil
+		return
+	}
+	*skill = &SkillRating{
+		Rating:  opts.SkillLevel(loss),
+		Error:   loss,
+		Derived: true,
+	}
+}
@@ -0,0 +1,209 @@
+package gnubgparser
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSkillAndLuckLevels(t *testing.T) {
+	opts := DefaultRatingOptions()
+
+	skillTests := []struct {
+		loss float64
+		want string
+	}{
+		{0, "None"},
+		{0.04, "None"},
+		{0.05, "Doubtful"},
+		{0.1, "Bad"},
+		{0.2, "VeryBad"},
+	}
+	for _, tt := range skillTests {
+		if got := opts.SkillLevel(tt.loss); got != tt.want {
+			t.Errorf("SkillLevel(%v) = %q, want %q", tt.loss, got, tt.want)
+		}
+	}
+
+	luckTests := []struct {
+		value float64
+		want  string
+	}{
+		{0, "None"},
+		{0.35, "Good"},
+		{0.7, "VeryGood"},
+		{-0.35, "Bad"},
+		{-0.7, "VeryBad"},
+	}
+	for _, tt := range luckTests {
+		if got := opts.LuckLevel(tt.value); got != tt.want {
+			t.Errorf("LuckLevel(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+// cubeDA builds a DA property value with the given no double and
+// double/take equities
+func cubeDA(nd, dt float64) string {
+	return fmt.Sprintf("DA[E ver 3 2C 1 0.000000 1 0.6 0.1 0 0.05 0 0.3 %f 0.6 0.1 0 0.05 0 0.3 %f]", nd, dt)
+}
+
+func TestRateMatch(t *testing.T) {
+	sgf := `(;FF[4]GM[6]RE[B+2]
+;B[41lpab]LU[0.45]
+;W[31fehe]` + cubeDA(0.5, 0.6) + `LU[-inf]
+;B[double]` + cubeDA(0.40, 0.30) + `
+;W[take]` + cubeDA(0.40, 0.30) + `
+;W[52lpab]SK[VeryBad 0.5]
+;W[double]` + cubeDA(0.5, 0.7) + `
+;B[drop]` + cubeDA(0.5, 0.7) + `)
+`
+	match, err := ParseSGF(strings.NewReader(sgf))
+	if err != nil {
+		t.Fatalf("ParseSGF failed: %v", err)
+	}
+	moves := match.Games[0].Moves
+
+	// Single-value LU is rated; LU[-inf] means not computed
+	if moves[0].Luck == nil || moves[0].Luck.Rating != "Good" || !moves[0].Luck.Derived {
+		t.Errorf("Luck = %+v, want derived Good", moves[0].Luck)
+	}
+	if moves[1].Luck != nil {
+		t.Errorf("LU[-inf] should leave Luck nil, got %+v", moves[1].Luck)
+	}
+
+	tests := []struct {
+		name   string
+		skill  *SkillRating
+		rating string
+		loss   float64
+	}{
+		{"missed double", moves[1].CubeSkill, "Bad", 0.1},
+		{"wrong double", moves[2].Skill, "Bad", 0.1},
+		{"skill from SK", moves[4].Skill, "VeryBad", 0.5},
+		{"wrong drop", moves[6].Skill, "VeryBad", 0.3},
+	}
+	for _, tt := range tests {
+		if tt.skill == nil {
+			t.Errorf("%s: Skill is nil", tt.name)
+			continue
+		}
+		if tt.skill.Rating != tt.rating || math.Abs(tt.skill.Error-tt.loss) > 1e-6 {
+			t.Errorf("%s: Skill = %+v, want %s %.2f", tt.name, tt.skill, tt.rating, tt.loss)
+		}
+	}
+	if moves[3].Skill != nil {
+		t.Errorf("Correct take should have no skill rating, got %+v", moves[3].Skill)
+	}
+	if moves[5].Skill != nil {
+		t.Errorf("Correct double should have no skill rating, got %+v", moves[5].Skill)
+	}
+
+	// Re-rating with other thresholds updates derived ratings only
+	opts := DefaultRatingOptions()
+	opts.Bad = 0.2
+	opts.VeryBad = 0.4
+	RateMatch(match, opts)
+	if got := moves[2].Skill.Rating; got != "Doubtful" {
+		t.Errorf("Re-rated double = %q, want Doubtful", got)
+	}
+	if got := moves[4].Skill.Rating; got != "VeryBad" || moves[4].Skill.Derived {
+		t.Errorf("SK rating should be kept, got %+v", moves[4].Skill)
+	}
+}
+
+func TestMatchEquity(t *testing.T) {
+	if got := MatchEquity(5, 5, false); got != 0.5 {
+		t.Errorf("MatchEquity(5, 5) = %v, want 0.5", got)
+	}
+	// Beyond the 15-away table the equities are extended
+	for a := 1; a <= 25; a++ {
+		for b := 1; b <= 25; b++ {
+			if sum := MatchEquity(a, b, false) + MatchEquity(b, a, false); math.Abs(sum-1) > 1e-9 {
+				t.Errorf("MatchEquity(%d, %d) is not symmetric", a, b)
+			}
+			if b > 1 && MatchEquity(a, b, false) <= MatchEquity(a, b-1, false) {
+				t.Errorf("MatchEquity(%d, %d) should grow with the opponent's away score", a, b)
+			}
+		}
+	}
+}
+
+func TestReadMET(t *testing.T) {
+	const file = `<?xml version="1.0" encoding="ISO-8859-1"?>
+<met>
+  <info><name>Tiny</name></info>
+  <pre-crawford-table type="explicit">
+    <row> <me>0.5</me> <me>0.7</me> </row>
+    <row> <me>0.3</me> <me>0.5</me> </row>
+  </pre-crawford-table>
+  <post-crawford-table player="both" type="explicit">
+    <row> <me>0.5</me> <me>0.45</me> </row>
+  </post-crawford-table>
+</met>`
+
+	met, err := ReadMET(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("ReadMET failed: %v", err)
+	}
+	tests := []struct {
+		away, oppAway int
+		crawfordDone  bool
+		want          float64
+	}{
+		{2, 1, false, 0.3},
+		{1, 2, false, 0.7},
+		{2, 1, true, 0.45},
+		{1, 2, true, 0.55},
+		{0, 2, false, 1},
+	}
+	for _, tt := range tests {
+		if got := met.Equity(tt.away, tt.oppAway, tt.crawfordDone); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Equity(%d, %d, %v) = %v, want %v", tt.away, tt.oppAway, tt.crawfordDone, got, tt.want)
+		}
+	}
+
+	// The match's table drives its match play conversions
+	match := &Match{Metadata: MatchMetadata{MatchLength: 2}, Games: []Game{{Winner: 0, Points: 1}}}
+	if got := MatchLuck(match).Result[0]; math.Abs(got-MatchEquity(1, 2, false)) > 1e-9 {
+		t.Errorf("Default result = %v", got)
+	}
+	match.MET = met
+	if got := MatchLuck(match).Result[0]; math.Abs(got-0.7) > 1e-9 {
+		t.Errorf("Result with the match's table = %v, want 0.7", got)
+	}
+
+	if _, err := ReadMET(strings.NewReader(`<met><pre-crawford-table type="zadeh"/></met>`)); err == nil {
+		t.Error("Expected an error for a generated table")
+	}
+}
+
+func TestMatchCubeEquities(t *testing.T) {
+	match, err := ParseSGFFile("test/charlot1-charlot2_7p_2025-11-08-2305.sgf")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	// Game 2 at 0-2 in a 7 point match: charlot1 redoubles to 4 and
+	// charlot2 passes. DA gives ND 0.490076 and DT 0.601236 as MWC.
+	game := &match.Games[1]
+	double, drop := &game.Moves[41], &game.Moves[42]
+	if double.Type != MoveTypeDouble || drop.Type != MoveTypeDrop {
+		t.Fatalf("Moves 42-43 = %s, %s, want double and drop", double.Type, drop.Type)
+	}
+	nd, dt, dp, ok := cubeEquities(drop, game, 7, 0, drop.Cube.Value, nil)
+	if !ok || math.Abs(nd-0.9234) > 1e-3 || math.Abs(dt-1.7817) > 1e-3 || dp != 1 {
+		t.Errorf("Cube equities = %.4f %.4f %.4f, want 0.9234 1.7817 1.0000", nd, dt, dp)
+	}
+	if double.Skill != nil || drop.Skill != nil {
+		t.Errorf("Skills = %+v, %+v, want a correct double and pass", double.Skill, drop.Skill)
+	}
+
+	perf := MatchPerformance(match)
+	for player, p := range perf.Players {
+		if p.CubePR() > 50 {
+			t.Errorf("Player %d cube PR = %.2f", player, p.CubePR())
+		}
+	}
+}
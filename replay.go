@@ -0,0 +1,374 @@
+package gnubgparser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// replayData is the JSON embedded in the HTML replayer
+type replayData struct {
+	Match  *Match        `json:"match"`
+	Names  [2]string     `json:"names"`
+	Games  []replayGame  `json:"games"`
+	Frames []replayFrame `json:"frames"`
+}
+
+// replayGame describes one game of the replayer
+type replayGame struct {
+	Title    string `json:"title"`
+	Result   string `json:"result"`
+	Checkers int    `json:"checkers"`
+}
+
+// replayFrame is one step of the replayer: a decision, or the final
+// position of a game (Move 0, Player -1)
+type replayFrame struct {
+	Game       int        `json:"game"`
+	Move       int        `json:"move"` // 1-based record number
+	Player     int        `json:"player"`
+	Board      [2][25]int `json:"board"` // As Position.Board
+	Dice       [2]int     `json:"dice"`
+	Cube       int        `json:"cube"`
+	CubeOwner  int        `json:"cube_owner"`
+	Score      [2]int     `json:"score"`
+	Hops       []SubMove  `json:"hops,omitempty"` // Checker play, from the mover's side
+	Header     string     `json:"header"`
+	Action     string     `json:"action"`
+	Severity   string     `json:"severity,omitempty"` // doubtful, bad or verybad
+	Analysis   string     `json:"analysis,omitempty"`
+	Comment    string     `json:"comment,omitempty"`
+	PositionID string     `json:"position_id"`
+	MatchID    string     `json:"match_id"`
+}
+
+// WriteHTMLReplay writes a single-file interactive replayer of a match: an
+// SVG board to step through every checker play and cube action, the
+// analysis of each decision, and buttons and keys to move between games and
+// to jump to blunders (bad and very bad decisions). The match JSON and the
+// script are embedded, so the page works offline.
+func WriteHTMLReplay(w io.Writer, match *Match) error {
+	data := newReplayData(match)
+	js, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return replayTemplate.Execute(w, struct {
+		Title string
+		Data  template.JS
+	}{data.Names[0] + " vs " + data.Names[1], template.JS(js)})
+}
+
+// newReplayData builds the frames of the replayer
+func newReplayData(match *Match) replayData {
+	names := playerNames(match)
+	matchLength := match.Metadata.MatchLength
+	data := replayData{Match: match, Names: names}
+
+	for g := range match.Games {
+		game := &match.Games[g]
+		positions := GamePositions(game, matchLength)
+		data.Games = append(data.Games, replayGame{
+			Title:    fmt.Sprintf("Game %d (%s %d, %s %d)", g+1, names[0], game.Score[0], names[1], game.Score[1]),
+			Result:   gameResultText(game, names),
+			Checkers: checkersPerSide(game.Variation),
+		})
+
+		for i := range game.Moves {
+			mr := &game.Moves[i]
+			pos := positions[i]
+			if mr.Player != 0 && mr.Player != 1 {
+				continue
+			}
+			header, action, ok := textDecision(mr, &pos, names[mr.Player])
+			if !ok {
+				continue
+			}
+			frame := newReplayFrame(g, &pos)
+			frame.Move = i + 1
+			frame.Player = mr.Player
+			frame.Header = header
+			frame.Action = action
+			frame.Severity = strings.ToLower(worstRating(mr))
+			frame.Comment = mr.Comment
+			frame.MatchID = pos.matchID(matchIDState{Turn: mr.Player, Doubled: mr.Type == MoveTypeTake || mr.Type == MoveTypeDrop})
+			if mr.Type == MoveTypeNormal {
+				after := pos
+				frame.Hops = after.applyMove(mr.Player, mr.Hops())
+			}
+
+			var buf bytes.Buffer
+			bw := bufio.NewWriter(&buf)
+			writeTextAlert(bw, mr)
+			if mr.Luck != nil {
+				fmt.Fprintf(bw, "Luck: %+.3f (%s)\n", mr.Luck.Value, textRating(mr.Luck.Rating))
+			}
+			if mr.Type == MoveTypeNormal && mr.Analysis != nil && len(mr.Analysis.Moves) > 0 {
+				fmt.Fprintln(bw)
+				writeTextMoves(bw, mr, &pos, 0)
+			}
+			if mr.CubeAnalysis != nil {
+				fmt.Fprintln(bw)
+				writeTextCube(bw, mr, game, matchLength, pos.CubeValue, match.MET)
+			}
+			bw.Flush()
+			frame.Analysis = strings.TrimSpace(buf.String())
+			data.Frames = append(data.Frames, frame)
+		}
+
+		final := finalPosition(game, positions, matchLength)
+		frame := newReplayFrame(g, &final)
+		frame.Player = -1
+		frame.Header = data.Games[g].Result
+		data.Frames = append(data.Frames, frame)
+	}
+	return data
+}
+
+// newReplayFrame returns a frame showing a position
+func newReplayFrame(game int, pos *Position) replayFrame {
+	return replayFrame{
+		Game:       game,
+		Board:      pos.Board,
+		Dice:       pos.Dice,
+		Cube:       pos.CubeValue,
+		CubeOwner:  pos.CubeOwner,
+		Score:      pos.Score,
+		PositionID: pos.PositionID(),
+		MatchID:    pos.MatchID(),
+	}
+}
+
+// replayTemplate is the replayer page. The board script uses the layout of
+// layoutDiagram, in units of a sixteenth of the width.
+var replayTemplate = template.Must(template.New("replay").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 1em 2em; color: #222; }
+#main { display: flex; gap: 1.5em; align-items: flex-start; }
+#board { width: 600px; max-width: 100%; }
+#controls { margin: 0.5em 0; }
+#controls button { margin-right: 0.2em; }
+#list { height: 640px; overflow-y: auto; min-width: 18em; border: 1px solid #ccc; }
+#list div { padding: 0.1em 0.5em; cursor: pointer; white-space: nowrap; }
+#list div.current { outline: 2px solid #1f6fd6; }
+.doubtful { background: #fff6d5; }
+.bad { background: #ffe0c0; }
+.verybad { background: #ffc8c8; }
+pre { background: #f6f6f6; padding: 0.5em; }
+.ids { font-family: monospace; color: #555; }
+.comment { font-style: italic; color: #555; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="ids" id="meta"></p>
+<div id="controls">
+<select id="game"></select>
+<button id="first" title="Home">|&lt;</button>
+<button id="prev" title="Left arrow">&lt;</button>
+<button id="next" title="Right arrow">&gt;</button>
+<button id="last" title="End">&gt;|</button>
+<button id="prevBlunder" title="b">&lt; Blunder</button>
+<button id="nextBlunder" title="B">Blunder &gt;</button>
+<button id="flip" title="f">Flip board</button>
+</div>
+<div id="main">
+<div>
+<svg id="board" viewBox="0 0 16 12.8"></svg>
+<h2 id="header"></h2>
+<p id="action"></p>
+<p class="ids" id="ids"></p>
+<pre id="analysis"></pre>
+<p class="comment" id="comment"></p>
+</div>
+<div id="list"></div>
+</div>
+<script>
+(function () {
+var data = {{.Data}};
+var colors = {frame: "#6b4423", board: "#e9dcbc", dark: "#9c4a2c", light: "#c8a878",
+	checkers: ["#f7f4ee", "#2b2b2b"], edge: "#202020", arrow: "#1f6fd6"};
+var cur = 0, bottom = 0;
+
+function $(id) { return document.getElementById(id); }
+
+// column returns the left edge of a point, indexed from the bottom player's side
+function column(i) {
+	if (i < 6) return 8.3 + (5 - i);
+	if (i < 12) return 1.3 + (11 - i);
+	if (i < 18) return 1.3 + (i - 12);
+	return 8.3 + (i - 18);
+}
+
+function drawBoard(f) {
+	var top = 1 - bottom, y0 = 0.9, y1 = 11.9, mid = 6.4, r = 0.45;
+	var s = [];
+	function rect(x, y, w, h, fill, stroke) {
+		s.push('<rect x="' + x + '" y="' + y + '" width="' + w + '" height="' + h + '" fill="' + fill + '"' +
+			(stroke ? ' stroke="' + stroke + '" stroke-width="0.03"' : '') + '/>');
+	}
+	function circle(x, y, rr, fill) {
+		s.push('<circle cx="' + x + '" cy="' + y + '" r="' + rr + '" fill="' + fill + '" stroke="' + colors.edge + '" stroke-width="0.04"/>');
+	}
+	function text(x, y, size, t, fill) {
+		s.push('<text x="' + x + '" y="' + y + '" font-size="' + size + '" text-anchor="middle" dominant-baseline="central" fill="' + fill + '">' + t + '</text>');
+	}
+	function stack(player, n, x, y, dir, max) {
+		var shown = Math.min(n, max);
+		for (var j = 0; j < shown; j++) {
+			var cy = y + dir * (r + j * 0.9);
+			circle(x, cy, r * 0.95, colors.checkers[player]);
+			if (j == shown - 1 && n > shown) text(x, cy, 0.4, n, colors.checkers[1 - player]);
+		}
+	}
+
+	s.push('<defs><marker id="head" markerWidth="4" markerHeight="4" refX="3" refY="2" orient="auto"><path d="M0,0 L4,2 L0,4 z" fill="' + colors.arrow + '"/></marker></defs>');
+	rect(1, 0.6, 15, 11.6, colors.frame);
+	rect(1.3, y0, 6, 11, colors.board);
+	rect(8.3, y0, 6, 11, colors.board);
+	rect(14.6, y0, 1, mid - 0.1 - y0, colors.board);
+	rect(14.6, mid + 0.1, 1, y1 - mid - 0.1, colors.board);
+	for (var i = 0; i < 24; i++) {
+		var x = column(i), base = i < 12 ? y1 : y0, tip = i < 12 ? y1 - 4.6 : y0 + 4.6;
+		s.push('<polygon points="' + x + ',' + base + ' ' + (x + 1) + ',' + base + ' ' + (x + 0.5) + ',' + tip +
+			'" fill="' + (i % 2 == 0 ? colors.dark : colors.light) + '"/>');
+		text(x + 0.5, i < 12 ? 12.5 : 0.3, 0.4, i + 1, "#222");
+		var edge = i < 12 ? y1 : y0, dir = i < 12 ? -1 : 1;
+		stack(bottom, f.board[bottom][i], x + 0.5, edge, dir, 5);
+		stack(top, f.board[top][23 - i], x + 0.5, edge, dir, 5);
+	}
+	stack(bottom, f.board[bottom][24], 7.8, mid + 0.6, 1, 4);
+	stack(top, f.board[top][24], 7.8, mid - 0.6, -1, 4);
+
+	var checkers = data.games[f.game].checkers;
+	[bottom, top].forEach(function (p) {
+		var on = 0;
+		for (var k = 0; k < 25; k++) on += f.board[p][k];
+		for (var j = 0; j < checkers - on; j++) {
+			rect(14.7, p == top ? y0 + 0.1 + j * 0.32 : y1 - 0.1 - (j + 1) * 0.32, 0.8, 0.3, colors.checkers[p], colors.edge);
+		}
+	});
+
+	if (f.cube > 0) {
+		var cy = f.cube_owner == bottom ? y1 - 0.6 : f.cube_owner == top ? y0 + 0.6 : mid;
+		var centred = f.cube_owner != 0 && f.cube_owner != 1;
+		rect(0.1, cy - 0.4, 0.8, 0.8, "#fff", colors.edge);
+		text(0.5, cy, 0.45, centred && f.cube == 1 ? 64 : f.cube, "#222");
+	}
+
+	var pips = {1: [[0, 0]], 2: [[-1, -1], [1, 1]], 3: [[-1, -1], [0, 0], [1, 1]],
+		4: [[-1, -1], [1, -1], [-1, 1], [1, 1]], 5: [[-1, -1], [1, -1], [0, 0], [-1, 1], [1, 1]],
+		6: [[-1, -1], [1, -1], [-1, 0], [1, 0], [-1, 1], [1, 1]]};
+	if (f.dice[0] > 0 && f.player >= 0) {
+		f.dice.forEach(function (d, k) {
+			var cx = 11.3 + (k - 0.5) * 1.2;
+			rect(cx - 0.4, mid - 0.4, 0.8, 0.8, colors.checkers[f.player], colors.edge);
+			(pips[d] || []).forEach(function (p) {
+				s.push('<circle cx="' + (cx + p[0] * 0.22) + '" cy="' + (mid + p[1] * 0.22) + '" r="0.08" fill="' + colors.checkers[1 - f.player] + '"/>');
+			});
+		});
+	}
+
+	// Arrows for the checker play, from the mover's side
+	function at(pt) {
+		var side = f.player == top ? -1 : 1;
+		if (pt == 24) return [7.8, mid + side * 1.5];
+		if (pt < 0) return [15.1, mid + side * 2.5];
+		var i = f.player == bottom ? pt : 23 - pt;
+		return [column(i) + 0.5, i >= 12 ? y0 + 2.2 : y1 - 2.2];
+	}
+	(f.hops || []).forEach(function (h) {
+		var a = at(h.from), b = at(h.to);
+		s.push('<line x1="' + a[0] + '" y1="' + a[1] + '" x2="' + b[0] + '" y2="' + b[1] +
+			'" stroke="' + colors.arrow + '" stroke-opacity="0.75" stroke-width="0.12" marker-end="url(#head)"/>');
+	});
+	$("board").innerHTML = s.join("");
+}
+
+function show(i) {
+	cur = Math.max(0, Math.min(data.frames.length - 1, i));
+	var f = data.frames[cur];
+	drawBoard(f);
+	$("game").value = f.game;
+	$("header").textContent = f.move ? "Move " + f.move + ": " + f.header : f.header;
+	$("action").textContent = f.action;
+	$("action").className = f.severity || "";
+	$("ids").textContent = "Position ID: " + f.position_id + "  Match ID: " + f.match_id;
+	$("analysis").textContent = f.analysis || "";
+	$("analysis").style.display = f.analysis ? "" : "none";
+	$("comment").textContent = f.comment || "";
+	drawList(f.game);
+}
+
+function drawList(game) {
+	var list = $("list");
+	if (list.dataset.game != String(game)) {
+		list.dataset.game = game;
+		list.innerHTML = "";
+		data.frames.forEach(function (f, i) {
+			if (f.game != game) return;
+			var row = document.createElement("div");
+			row.textContent = f.move ? f.move + ". " + f.action : f.header;
+			row.className = f.severity || "";
+			row.dataset.frame = i;
+			row.onclick = function () { show(i); };
+			list.appendChild(row);
+		});
+	}
+	Array.prototype.forEach.call(list.children, function (row) {
+		var current = row.dataset.frame == String(cur);
+		row.classList.toggle("current", current);
+		if (current) row.scrollIntoView({block: "nearest"});
+	});
+}
+
+function blunder(dir) {
+	for (var i = cur + dir; i >= 0 && i < data.frames.length; i += dir) {
+		var sev = data.frames[i].severity;
+		if (sev == "bad" || sev == "verybad") return show(i);
+	}
+}
+
+function gameStart(g) {
+	for (var i = 0; i < data.frames.length; i++) if (data.frames[i].game == g) return i;
+	return cur;
+}
+
+var md = data.match.metadata;
+$("meta").textContent = [md.match_length > 0 ? md.match_length + " point match" : "Money session",
+	md.event, md.date].filter(Boolean).join(" - ");
+
+data.games.forEach(function (g, i) {
+	var opt = document.createElement("option");
+	opt.value = i;
+	opt.textContent = g.title;
+	$("game").appendChild(opt);
+});
+$("game").onchange = function () { show(gameStart(Number(this.value))); };
+$("first").onclick = function () { show(0); };
+$("prev").onclick = function () { show(cur - 1); };
+$("next").onclick = function () { show(cur + 1); };
+$("last").onclick = function () { show(data.frames.length - 1); };
+$("prevBlunder").onclick = function () { blunder(-1); };
+$("nextBlunder").onclick = function () { blunder(1); };
+$("flip").onclick = function () { bottom = 1 - bottom; show(cur); };
+document.addEventListener("keydown", function (e) {
+	var keys = {ArrowLeft: function () { show(cur - 1); }, ArrowRight: function () { show(cur + 1); },
+		Home: function () { show(0); }, End: function () { show(data.frames.length - 1); },
+		b: function () { blunder(-1); }, B: function () { blunder(1); }, f: function () { $("flip").onclick(); }};
+	if (keys[e.key] && e.target.tagName != "SELECT") { keys[e.key](); e.preventDefault(); }
+});
+show(0);
+})();
+</script>
+</body>
+</html>
+`))
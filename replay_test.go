@@ -0,0 +1,79 @@
+package gnubgparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTMLReplay(t *testing.T) {
+	match := &Match{
+		Metadata: MatchMetadata{Player1: "Alice <A>", Player2: "Bob"},
+		Games: []Game{{Winner: 0, Points: 2, ResultKind: ResultDroppedDouble, Moves: []MoveRecord{
+			{Type: MoveTypeNormal, Player: 0, Dice: [2]int{3, 1}, SubMoves: []SubMove{{From: 7, To: 4}, {From: 5, To: 4}},
+				Analysis: &MoveAnalysis{Moves: []MoveOption{{Move: [8]int{-1}, MoveString: "8/5 6/5", Equity: 0.15}}}},
+			{Type: MoveTypeNormal, Player: 1, Dice: [2]int{6, 5}, SubMoves: []SubMove{{From: 23, To: 12}},
+				Skill: &SkillRating{Rating: "VeryBad", Error: 0.2}, Comment: "</script><b>"},
+			{Type: MoveTypeDouble, Player: 0},
+			{Type: MoveTypeDrop, Player: 1},
+		}}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteHTMLReplay(&buf, match); err != nil {
+		t.Fatalf("WriteHTMLReplay failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{"<!DOCTYPE html>", "<title>Alice &lt;A&gt; vs Bob</title>", `<svg id="board"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Replayer does not contain %q", want)
+		}
+	}
+	// Everything is inline, and embedded text cannot close the script
+	for _, bad := range []string{"<link", "src=", "http", "</script><b>"} {
+		if strings.Contains(out, bad) {
+			t.Errorf("Replayer contains %q", bad)
+		}
+	}
+
+	start := strings.Index(out, "var data = ") + len("var data = ")
+	end := strings.Index(out[start:], ";\n") + start
+	var data replayData
+	if err := json.Unmarshal([]byte(out[start:end]), &data); err != nil {
+		t.Fatalf("Embedded data is not valid JSON: %v", err)
+	}
+	if data.Match == nil || len(data.Match.Games) != 1 || data.Match.Metadata.Player1 != match.Metadata.Player1 {
+		t.Errorf("Embedded match is missing")
+	}
+	if len(data.Games) != 1 {
+		t.Errorf("Embedded games = %d, want 1", len(data.Games))
+	}
+
+	// The four decisions and the final position
+	want := []struct {
+		header, severity string
+	}{
+		{"Alice <A> to play 31", ""},
+		{"Bob to play 65", "verybad"},
+		{"Alice <A> doubles to 2", ""},
+		{"Bob passes", ""},
+		{"Alice <A> wins 2 points (dropped double)", ""},
+	}
+	if len(data.Frames) != len(want) {
+		t.Fatalf("Got %d frames, want %d", len(data.Frames), len(want))
+	}
+	for i, w := range want {
+		f := data.Frames[i]
+		if f.Header != w.header || f.Severity != w.severity {
+			t.Errorf("Frame %d = %q (%q), want %q (%q)", i, f.Header, f.Severity, w.header, w.severity)
+		}
+	}
+	if len(data.Frames[0].Hops) != 2 || !strings.Contains(data.Frames[0].Analysis, "8/5 6/5") {
+		t.Errorf("First frame hops %v, analysis %q", data.Frames[0].Hops, data.Frames[0].Analysis)
+	}
+	if last := data.Frames[4]; last.Player != -1 || last.Board[0][4] != 2 || last.Board[1][12] != 6 {
+		t.Errorf("Final frame does not show the position after the moves: %+v", last)
+	}
+}
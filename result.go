@@ -0,0 +1,176 @@
+package gnubgparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resignLevelNames maps resignation levels to their usual names
+var resignLevelNames = [4]string{"", "single", "gammon", "backgammon"}
+
+// parseResignLevel converts "single", "gammon", "backgammon" or "1"-"3"
+// to a resignation level. It returns 0 if the value is not recognised.
+func parseResignLevel(s string) int {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for level := 1; level <= 3; level++ {
+		if s == resignLevelNames[level] || s == fmt.Sprint(level) {
+			return level
+		}
+	}
+	return 0
+}
+
+// checkersPerSide returns the number of checkers each player starts with
+func checkersPerSide(variation string) int {
+	switch variation {
+	case "Hypergammon1":
+		return 1
+	case "Hypergammon2":
+		return 2
+	case "Hypergammon3":
+		return 3
+	}
+	return 15
+}
+
+// checkersBorneOff counts the checkers a player bore off during the game.
+// It returns false when the count cannot be trusted because the game
+// contains a board setup.
+func checkersBorneOff(game *Game, player int) (int, bool) {
+	off := 0
+	for _, mr := range game.Moves {
+		if mr.Type == MoveTypeSetBoard {
+			return 0, false
+		}
+		if mr.Type != MoveTypeNormal || mr.Player != player {
+			continue
+		}
+		if mr.SubMoves != nil {
+			// MAT: off is -1
+			for _, sm := range mr.SubMoves {
+				if sm.To == -1 {
+					off++
+				}
+			}
+			continue
+		}
+		// SGF: off is 25
+		for i := 0; i < 8; i += 2 {
+			if mr.Move[i] >= 0 && mr.Move[i+1] == 25 {
+				off++
+			}
+		}
+	}
+	return off, true
+}
+
+// lastResignation returns the resignation that ended the game: the last
+// resignation offer that was not rejected, or nil.
+func lastResignation(game *Game) *MoveRecord {
+	var resign *MoveRecord
+	for i := range game.Moves {
+		switch game.Moves[i].Type {
+		case MoveTypeResign:
+			resign = &game.Moves[i]
+		case MoveTypeReject:
+			resign = nil
+		}
+	}
+	return resign
+}
+
+// checkGames stores the cube states and classifies the results of a
+// match's games, adding a warning for each inconsistency
+func checkGames(match *Match) {
+	for i := range match.Games {
+		game := &match.Games[i]
+		for _, warning := range trackCube(game) {
+			match.Warnings = append(match.Warnings, fmt.Sprintf("game %d: %s", game.GameNumber, warning))
+		}
+		if warning := classifyResult(game, match.Metadata.MatchLength); warning != "" {
+			match.Warnings = append(match.Warnings, fmt.Sprintf("game %d: %s", game.GameNumber, warning))
+		}
+	}
+}
+
+// classifyResult sets ResultKind for a finished game and cross-checks the
+// points won against the final cube value. Resignations are recognised from
+// resignation records, the Resigned flag, or a winner who did not bear off
+// all checkers. Points may be lower than the full value when they are
+// capped by the match length. It returns a description of any
+// inconsistency, or "" when the result is consistent.
+func classifyResult(game *Game, matchLength int) string {
+	if game.Winner < 0 || game.Winner > 1 {
+		return ""
+	}
+
+	cube, dropped := finalCubeValue(game)
+	points := game.Points
+	capped := matchLength > 0 && points > 0 && points == matchLength-game.Score[game.Winner]
+
+	// consistent reports whether the points won match the expected value
+	consistent := func(expected int) bool {
+		return points == expected || (capped && points < expected)
+	}
+
+	lastType := MoveType("")
+	if n := len(game.Moves); n > 0 {
+		lastType = game.Moves[n-1].Type
+	}
+
+	resign := lastResignation(game)
+	off, counted := checkersBorneOff(game, game.Winner)
+
+	switch {
+	case dropped && lastType == MoveTypeDrop:
+		game.ResultKind = ResultDroppedDouble
+		if !consistent(cube) {
+			return fmt.Sprintf("dropped double at cube %d but %d points won", cube, points)
+		}
+
+	case resign != nil || game.Resigned ||
+		(counted && off < checkersPerSide(game.Variation) && len(game.Moves) > 0):
+		game.ResultKind = ResultResignation
+		game.Resigned = true
+
+		level := 0
+		if resign != nil {
+			level = resign.ResignLevel
+		}
+		if level == 0 {
+			if points%cube != 0 || points/cube < 1 || points/cube > 3 {
+				if !capped {
+					return fmt.Sprintf("resignation worth %d points does not match cube %d", points, cube)
+				}
+				return ""
+			}
+			level = points / cube
+			if resign != nil && !capped {
+				resign.ResignLevel = level
+			}
+		}
+		if !consistent(level * cube) {
+			return fmt.Sprintf("%s resignation at cube %d but %d points won", resignLevelNames[level], cube, points)
+		}
+
+	default:
+		multiplier := points / cube
+		valid := points%cube == 0 && multiplier >= 1 && multiplier <= 3
+		if !valid {
+			// Smallest result reaching the points won
+			multiplier = (points + cube - 1) / cube
+			if multiplier < 1 {
+				multiplier = 1
+			}
+			if multiplier > 3 {
+				multiplier = 3
+			}
+		}
+		game.ResultKind = []ResultKind{ResultSingle, ResultGammon, ResultBackgammon}[multiplier-1]
+		if !valid && !capped {
+			return fmt.Sprintf("%d points won does not match cube %d", points, cube)
+		}
+	}
+
+	return ""
+}
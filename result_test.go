@@ -0,0 +1,215 @@
+package gnubgparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassifyResult(t *testing.T) {
+	// bearOff builds a game in which player 0 bore off n checkers
+	bearOff := func(n int) []MoveRecord {
+		var moves []MoveRecord
+		for i := 0; i < n; i++ {
+			moves = append(moves, MoveRecord{
+				Type:     MoveTypeNormal,
+				Player:   0,
+				SubMoves: []SubMove{{From: 0, To: -1}},
+			})
+		}
+		return moves
+	}
+	doubleTake := []MoveRecord{
+		{Type: MoveTypeDouble, Player: 1, CubeValue: 2},
+		{Type: MoveTypeTake, Player: 0},
+	}
+
+	tests := []struct {
+		name        string
+		game        Game
+		matchLength int
+		wantKind    ResultKind
+		wantWarning bool
+	}{
+		{
+			name:     "single",
+			game:     Game{Winner: 0, Points: 1, Moves: bearOff(15)},
+			wantKind: ResultSingle,
+		},
+		{
+			name:     "gammon with cube",
+			game:     Game{Winner: 0, Points: 4, Moves: append(append([]MoveRecord{}, doubleTake...), bearOff(15)...)},
+			wantKind: ResultGammon,
+		},
+		{
+			name:     "backgammon",
+			game:     Game{Winner: 0, Points: 3, Moves: bearOff(15)},
+			wantKind: ResultBackgammon,
+		},
+		{
+			name: "dropped double",
+			game: Game{Winner: 0, Points: 1, Moves: []MoveRecord{
+				{Type: MoveTypeDouble, Player: 0, CubeValue: 2},
+				{Type: MoveTypeDrop, Player: 1},
+			}},
+			wantKind: ResultDroppedDouble,
+		},
+		{
+			name: "dropped double with wrong points",
+			game: Game{Winner: 0, Points: 2, Moves: []MoveRecord{
+				{Type: MoveTypeDouble, Player: 0, CubeValue: 2},
+				{Type: MoveTypeDrop, Player: 1},
+			}},
+			wantKind:    ResultDroppedDouble,
+			wantWarning: true,
+		},
+		{
+			name:     "winner did not bear off",
+			game:     Game{Winner: 0, Points: 2, Moves: bearOff(3)},
+			wantKind: ResultResignation,
+		},
+		{
+			name: "gammon resignation record",
+			game: Game{Winner: 0, Points: 2, Moves: []MoveRecord{
+				{Type: MoveTypeResign, Player: 1, ResignLevel: 2},
+				{Type: MoveTypeAccept, Player: 0},
+			}},
+			wantKind: ResultResignation,
+		},
+		{
+			name: "resignation level does not match points",
+			game: Game{Winner: 0, Points: 1, Moves: []MoveRecord{
+				{Type: MoveTypeResign, Player: 1, ResignLevel: 2},
+				{Type: MoveTypeAccept, Player: 0},
+			}},
+			wantKind:    ResultResignation,
+			wantWarning: true,
+		},
+		{
+			name:        "points capped by match length",
+			game:        Game{Winner: 0, Points: 1, Score: [2]int{4, 2}, Moves: append(append([]MoveRecord{}, doubleTake...), bearOff(15)...)},
+			matchLength: 5,
+			wantKind:    ResultSingle,
+		},
+		{
+			name:        "points not a multiple of the cube",
+			game:        Game{Winner: 0, Points: 3, Moves: append(append([]MoveRecord{}, doubleTake...), bearOff(15)...)},
+			matchLength: 7,
+			wantKind:    ResultGammon,
+			wantWarning: true,
+		},
+		{
+			name: "unfinished game",
+			game: Game{Winner: -1, Moves: bearOff(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := tt.game
+			warning := classifyResult(&game, tt.matchLength)
+			if game.ResultKind != tt.wantKind {
+				t.Errorf("ResultKind = %q, want %q", game.ResultKind, tt.wantKind)
+			}
+			if (warning != "") != tt.wantWarning {
+				t.Errorf("warning = %q, want warning: %v", warning, tt.wantWarning)
+			}
+			if tt.wantKind == ResultResignation && !game.Resigned {
+				t.Error("Resigned should be set for resignations")
+			}
+		})
+	}
+}
+
+func TestSGFResignationRecords(t *testing.T) {
+	sgf := `(;FF[4]GM[6]MI[length:7][game:0][ws:0][bs:0]RE[B+2R]
+;B[41lpab]
+;W[31fehe])
+(;FF[4]GM[6]MI[length:7][game:1][ws:0][bs:2]RE[W+1]
+;B[41lpab]
+;W[resign gammon]
+;B[reject]
+;W[31fehe]
+;B[resign]
+;W[accept])
+`
+	match, err := ParseSGF(strings.NewReader(sgf))
+	if err != nil {
+		t.Fatalf("ParseSGF failed: %v", err)
+	}
+
+	// RE[...R] is restored as a resignation by the loser and its acceptance
+	game := match.Games[0]
+	n := len(game.Moves)
+	if n < 2 || game.Moves[n-2].Type != MoveTypeResign || game.Moves[n-1].Type != MoveTypeAccept {
+		t.Fatalf("Game 1 should end with resign/accept, got %+v", game.Moves)
+	}
+	if game.Moves[n-2].Player != 0 || game.Moves[n-2].ResignLevel != 2 {
+		t.Errorf("Resignation = %+v, want gammon by player 0", game.Moves[n-2])
+	}
+	if game.ResultKind != ResultResignation {
+		t.Errorf("ResultKind = %q, want %q", game.ResultKind, ResultResignation)
+	}
+
+	// Explicit resignation records: the rejected gammon offer is not the result
+	game = match.Games[1]
+	var types []MoveType
+	for _, mr := range game.Moves {
+		types = append(types, mr.Type)
+	}
+	want := []MoveType{MoveTypeNormal, MoveTypeResign, MoveTypeReject, MoveTypeNormal, MoveTypeResign, MoveTypeAccept}
+	if len(types) != len(want) {
+		t.Fatalf("Move types = %v, want %v", types, want)
+	}
+	if game.Moves[1].ResignLevel != 2 {
+		t.Errorf("Rejected resignation level = %d, want 2", game.Moves[1].ResignLevel)
+	}
+	if game.Moves[4].ResignLevel != 1 {
+		t.Errorf("Accepted resignation level = %d, want 1 (derived from points)", game.Moves[4].ResignLevel)
+	}
+	if len(match.Warnings) != 0 {
+		t.Errorf("Unexpected warnings: %v", match.Warnings)
+	}
+}
+
+func TestMATResignationAndResult(t *testing.T) {
+	matContent := ` 5 point match
+
+ Game 1
+ alice : 0                   bob : 0
+  1)                             41: 13/9 24/23
+  2) 31: 8/5 6/5                 Resigns gammon
+  3)  Rejects                    62: 24/18 13/11
+  4) 43: 8/4 6/3                 Resigns
+  5)  Accepts
+                                  Wins 1 point
+
+ Game 2
+ alice : 0                   bob : 1
+  1) 31: 8/5 6/5                 Doubles => 2
+  2)  Drops
+      Wins 1 point
+`
+	match, err := ParseMAT(strings.NewReader(matContent))
+	if err != nil {
+		t.Fatalf("ParseMAT failed: %v", err)
+	}
+
+	game := match.Games[0]
+	if game.Winner != 0 || game.Points != 1 {
+		t.Errorf("Game 1 winner/points = %d/%d, want 0/1", game.Winner, game.Points)
+	}
+	if game.ResultKind != ResultResignation || !game.Resigned {
+		t.Errorf("Game 1 ResultKind = %q, Resigned = %v", game.ResultKind, game.Resigned)
+	}
+	if game.Moves[2].Type != MoveTypeResign || game.Moves[2].ResignLevel != 2 {
+		t.Errorf("Gammon resignation = %+v", game.Moves[2])
+	}
+
+	game = match.Games[1]
+	if game.ResultKind != ResultDroppedDouble || game.Points != 1 {
+		t.Errorf("Game 2 ResultKind = %q, points = %d", game.ResultKind, game.Points)
+	}
+	if len(match.Warnings) != 0 {
+		t.Errorf("Unexpected warnings: %v", match.Warnings)
+	}
+}
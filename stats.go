@@ -0,0 +1,360 @@
+package gnubgparser
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// closeCubeThreshold is the margin by which not doubling may beat doubling
+// for the decision to still count as a close cube decision (gnuBG's value)
+const closeCubeThreshold = 0.16
+
+// SeverityCounts counts errors by skill rating
+type SeverityCounts struct {
+	Doubtful int `json:"doubtful"`
+	Bad      int `json:"bad"`
+	VeryBad  int `json:"very_bad"`
+}
+
+// add counts one skill rating
+func (c *SeverityCounts) add(skill *SkillRating) {
+	if skill == nil {
+		return
+	}
+	switch skill.Rating {
+	case "Doubtful":
+		c.Doubtful++
+	case "Bad":
+		c.Bad++
+	case "VeryBad":
+		c.VeryBad++
+	}
+}
+
+// PlayerPerformance holds one player's error statistics over a game or a
+// match. Errors are equity losses in EMG, normalized to the cube.
+type PlayerPerformance struct {
+	TotalMoves       int            `json:"total_moves"`       // Checker plays, forced ones included
+	CheckerDecisions int            `json:"checker_decisions"` // Unforced analysed checker plays
+	CubeDecisions    int            `json:"cube_decisions"`    // Analysed cube actions and close no-double decisions
+	CheckerError     float64        `json:"checker_error"`
+	CubeError        float64        `json:"cube_error"`
+	CheckerCounts    SeverityCounts `json:"checker_counts"`
+	CubeCounts       SeverityCounts `json:"cube_counts"`
+	MissedDoubles    int            `json:"missed_doubles"`
+	WrongDoubles     int            `json:"wrong_doubles"`
+	WrongTakes       int            `json:"wrong_takes"`
+	WrongPasses      int            `json:"wrong_passes"`
+}
+
+// UnforcedDecisions returns the number of unforced checker and cube decisions
+func (p PlayerPerformance) UnforcedDecisions() int {
+	return p.CheckerDecisions + p.CubeDecisions
+}
+
+// TotalError returns the total checker and cube error in EMG
+func (p PlayerPerformance) TotalError() float64 {
+	return p.CheckerError + p.CubeError
+}
+
+// AverageError returns the error per unforced decision in EMG
+func (p PlayerPerformance) AverageError() float64 {
+	return ratio(p.TotalError(), p.UnforcedDecisions())
+}
+
+// AverageErrorMilli returns the error per unforced decision in mEMG
+// (thousandths of EMG)
+func (p PlayerPerformance) AverageErrorMilli() float64 {
+	return 1000 * p.AverageError()
+}
+
+// PR returns the XG-style performance rating: the average error per
+// unforced decision times 500. Lower is better.
+func (p PlayerPerformance) PR() float64 {
+	return 500 * p.AverageError()
+}
+
+// CheckerPR returns the performance rating of checker play alone
+func (p PlayerPerformance) CheckerPR() float64 {
+	return 500 * ratio(p.CheckerError, p.CheckerDecisions)
+}
+
+// CubePR returns the performance rating of cube decisions alone
+func (p PlayerPerformance) CubePR() float64 {
+	return 500 * ratio(p.CubeError, p.CubeDecisions)
+}
+
+// add accumulates another performance of the same player
+func (p *PlayerPerformance) add(o PlayerPerformance) {
+	p.TotalMoves += o.TotalMoves
+	p.CheckerDecisions += o.CheckerDecisions
+	p.CubeDecisions += o.CubeDecisions
+	p.CheckerError += o.CheckerError
+	p.CubeError += o.CubeError
+	p.CheckerCounts.Doubtful += o.CheckerCounts.Doubtful
+	p.CheckerCounts.Bad += o.CheckerCounts.Bad
+	p.CheckerCounts.VeryBad += o.CheckerCounts.VeryBad
+	p.CubeCounts.Doubtful += o.CubeCounts.Doubtful
+	p.CubeCounts.Bad += o.CubeCounts.Bad
+	p.CubeCounts.VeryBad += o.CubeCounts.VeryBad
+	p.MissedDoubles += o.MissedDoubles
+	p.WrongDoubles += o.WrongDoubles
+	p.WrongTakes += o.WrongTakes
+	p.WrongPasses += o.WrongPasses
+}
+
+// Performance holds both players' error statistics
+type Performance struct {
+	Players [2]PlayerPerformance `json:"players"`
+}
+
+// SnowieErrorRate returns a player's total error in mEMG divided by the
+// number of checker plays made by both players, as reported by Snowie
+// and gnuBG
+func (p Performance) SnowieErrorRate(player int) float64 {
+	moves := p.Players[0].TotalMoves + p.Players[1].TotalMoves
+	return 1000 * ratio(p.Players[player].TotalError(), moves)
+}
+
+// GamePerformance computes both players' error statistics for a game from
+// the skill ratings of its decisions (see RateMatch)
+func GamePerformance(game *Game, matchLength int) Performance {
+	return gamePerformance(game, matchLength, nil)
+}
+
+// gamePerformance computes GamePerformance with the match equity table met
+func gamePerformance(game *Game, matchLength int, met *MatchEquityTable) Performance {
+	var perf Performance
+	cubes, _, _ := replayCube(game)
+
+	for i := range game.Moves {
+		mr := &game.Moves[i]
+		if mr.Player < 0 || mr.Player > 1 {
+			continue
+		}
+		p := &perf.Players[mr.Player]
+
+		switch mr.Type {
+		case MoveTypeNormal:
+			p.TotalMoves++
+			if mr.Analysis != nil && len(mr.Analysis.Moves) > 1 {
+				p.CheckerDecisions++
+				if mr.Skill != nil {
+					p.CheckerError += mr.Skill.Error
+					p.CheckerCounts.add(mr.Skill)
+				}
+			}
+			if mr.CubeSkill != nil {
+				p.CubeDecisions++
+				p.CubeError += mr.CubeSkill.Error
+				p.CubeCounts.add(mr.CubeSkill)
+				p.MissedDoubles++
+			} else if nd, dt, dp, ok := cubeEquities(mr, game, matchLength, mr.Player, cubes[i].Value, met); ok &&
+				nd-math.Min(dt, dp) < closeCubeThreshold {
+				p.CubeDecisions++
+			}
+
+		case MoveTypeDouble, MoveTypeTake, MoveTypeDrop:
+			if mr.CubeAnalysis == nil && mr.Skill == nil {
+				continue
+			}
+			p.CubeDecisions++
+			if mr.Skill == nil {
+				continue
+			}
+			p.CubeError += mr.Skill.Error
+			p.CubeCounts.add(mr.Skill)
+			switch mr.Type {
+			case MoveTypeDouble:
+				p.WrongDoubles++
+			case MoveTypeTake:
+				p.WrongTakes++
+			case MoveTypeDrop:
+				p.WrongPasses++
+			}
+		}
+	}
+
+	return perf
+}
+
+// MatchPerformance computes both players' error statistics over all games
+func MatchPerformance(match *Match) Performance {
+	var perf Performance
+	for i := range match.Games {
+		game := gamePerformance(&match.Games[i], match.Metadata.MatchLength, match.MET)
+		perf.Players[0].add(game.Players[0])
+		perf.Players[1].add(game.Players[1])
+	}
+	return perf
+}
+
+// computeGameStatistic fills a GameStatistic from the game's decisions,
+// using the field meanings of gnuBG's GS property. The sections recorded in
+// a GS property replace the computed ones.
+func computeGameStatistic(game *Game, matchLength int, met *MatchEquityTable) GameStatistic {
+	var stat GameStatistic
+	perf := gamePerformance(game, matchLength, met)
+
+	for _, mr := range game.Moves {
+		if mr.Type == MoveTypeNormal && mr.Analysis != nil {
+			stat.HasMoves = true
+			if len(mr.Analysis.Moves) <= 1 && mr.Player >= 0 && mr.Player <= 1 {
+				stat.Moves.Forced[mr.Player]++
+			}
+		}
+		if mr.CubeAnalysis != nil {
+			stat.HasCube = true
+		}
+		if mr.Luck != nil {
+			stat.HasDice = true
+			if mr.Type == MoveTypeNormal && mr.Player >= 0 && mr.Player <= 1 {
+				stat.Luck[mr.Player].add(mr.Luck)
+			}
+		}
+	}
+
+	for player, p := range perf.Players {
+		stat.Moves.Unforced[player] = p.CheckerDecisions
+		stat.Moves.Doubtful[player] = p.CheckerCounts.Doubtful
+		stat.Moves.Bad[player] = p.CheckerCounts.Bad
+		stat.Moves.VeryBad[player] = p.CheckerCounts.VeryBad
+		stat.Moves.ErrorTotal[player] = p.CheckerError
+
+		stat.Cube.Unforced[player] = p.CubeDecisions
+		stat.Cube.Doubtful[player] = p.CubeCounts.Doubtful
+		stat.Cube.Bad[player] = p.CubeCounts.Bad
+		stat.Cube.VeryBad[player] = p.CubeCounts.VeryBad
+		stat.Cube.ErrorTotal[player] = p.CubeError
+		stat.Cube.MissedDouble[player] = p.MissedDoubles
+		stat.Cube.WrongDouble[player] = p.WrongDoubles
+		stat.Cube.WrongTake[player] = p.WrongTakes
+		stat.Cube.WrongPass[player] = p.WrongPasses
+	}
+
+	if game.recorded != nil {
+		game.recorded.apply(&stat)
+	}
+	return stat
+}
+
+// recordedStatistic holds the sections of gnuBG's GS property: M (checker
+// play), C (cube) and D (luck)
+type recordedStatistic struct {
+	moves, cube, dice bool
+	luckTotal         bool // The D section has finite luck totals
+	stat              GameStatistic
+}
+
+// parseGameStatistic reads the values of a GS property. gnuBG writes each
+// count for both players in turn, and each error total as EMG then MWC (or
+// points) for player 0, then for player 1.
+func parseGameStatistic(values []string) *recordedStatistic {
+	rec := &recordedStatistic{}
+	for _, value := range values {
+		section, fields, ok := strings.Cut(value, ":")
+		if !ok {
+			continue
+		}
+		nums := make([]float64, 0, 44)
+		for _, f := range strings.Fields(fields) {
+			n, err := strconv.ParseFloat(f, 64)
+			if err != nil {
+				break
+			}
+			nums = append(nums, n)
+		}
+		pairs := func(i int) [2]int { return [2]int{int(nums[2*i]), int(nums[2*i+1])} }
+		emg := func(i int) [2]float64 { return [2]float64{nums[i], nums[i+2]} }
+
+		switch {
+		case section == "M" && len(nums) >= 16:
+			m := &rec.stat.Moves
+			m.Unforced = pairs(0)
+			total := pairs(1)
+			m.Forced = [2]int{total[0] - m.Unforced[0], total[1] - m.Unforced[1]}
+			m.VeryBad, m.Bad, m.Doubtful = pairs(2), pairs(3), pairs(4)
+			m.ErrorTotal = emg(12)
+			rec.moves = true
+		case section == "C" && len(nums) >= 44:
+			c := &rec.stat.Cube
+			c.Unforced = pairs(0)
+			for p := 0; p < 2; p++ {
+				c.MissedDouble[p] = pairs(4)[p] + pairs(5)[p]
+				c.WrongDouble[p] = pairs(6)[p] + pairs(7)[p]
+				for i := 0; i < 6; i++ {
+					c.ErrorTotal[p] += emg(20 + 4*i)[p]
+				}
+			}
+			c.WrongTake, c.WrongPass = pairs(8), pairs(9)
+			rec.cube = true
+		case section == "D" && len(nums) >= 10:
+			l := &rec.stat.Luck
+			for p := 0; p < 2; p++ {
+				l[p].VeryBad, l[p].Bad, l[p].None = pairs(0)[p], pairs(1)[p], pairs(2)[p]
+				l[p].Good, l[p].VeryGood = pairs(3)[p], pairs(4)[p]
+			}
+			if len(nums) >= 14 && !math.IsInf(nums[10], 0) && !math.IsInf(nums[12], 0) {
+				total := emg(10)
+				l[0].Total, l[1].Total = total[0], total[1]
+				rec.luckTotal = true
+			}
+			rec.dice = true
+		}
+	}
+	return rec
+}
+
+// apply replaces the sections of stat recorded in the GS property. The
+// cube skill counts and the squared luck totals are not recorded, so they
+// keep their computed values.
+func (rec *recordedStatistic) apply(stat *GameStatistic) {
+	if rec.moves {
+		stat.HasMoves = true
+		stat.Moves = rec.stat.Moves
+	}
+	if rec.cube {
+		stat.HasCube = true
+		c, r := &stat.Cube, &rec.stat.Cube
+		c.Unforced, c.ErrorTotal = r.Unforced, r.ErrorTotal
+		c.MissedDouble, c.WrongDouble = r.MissedDouble, r.WrongDouble
+		c.WrongTake, c.WrongPass = r.WrongTake, r.WrongPass
+	}
+	if rec.dice {
+		stat.HasDice = true
+		for p := range stat.Luck {
+			l, r := &stat.Luck[p], rec.stat.Luck[p]
+			l.VeryBad, l.Bad, l.None, l.Good, l.VeryGood = r.VeryBad, r.Bad, r.None, r.Good, r.VeryGood
+			if rec.luckTotal {
+				l.Total = r.Total
+			}
+		}
+	}
+}
+
+// add counts one rated roll
+func (l *LuckStatistic) add(luck *LuckRating) {
+	switch luck.Rating {
+	case "VeryBad":
+		l.VeryBad++
+	case "Bad":
+		l.Bad++
+	case "Good":
+		l.Good++
+	case "VeryGood":
+		l.VeryGood++
+	default:
+		l.None++
+	}
+	l.Total += luck.Value
+	l.TotalSq += luck.Value * luck.Value
+}
+
+// ratio divides a total by a count, returning 0 for an empty count
+func ratio(total float64, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
@@ -0,0 +1,125 @@
+package gnubgparser
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGamePerformance(t *testing.T) {
+	twoOptions := &MoveAnalysis{Moves: make([]MoveOption, 2)}
+	forced := &MoveAnalysis{Moves: make([]MoveOption, 1)}
+
+	game := Game{Moves: []MoveRecord{
+		{Type: MoveTypeNormal, Player: 0, Analysis: twoOptions,
+			Skill: &SkillRating{Rating: "Bad", Error: 0.1}},
+		{Type: MoveTypeNormal, Player: 1, Analysis: twoOptions},
+		{Type: MoveTypeNormal, Player: 0, Analysis: forced},
+		// Missed double before a checker play
+		{Type: MoveTypeNormal, Player: 1, Analysis: twoOptions,
+			CubeAnalysis: &CubeAnalysis{CubefulNoDouble: 0.5, CubefulDoubleTake: 0.7, CubefulDoublePass: 1},
+			CubeSkill:    &SkillRating{Rating: "VeryBad", Error: 0.2}},
+		// Clear no double is not a cube decision
+		{Type: MoveTypeNormal, Player: 0, Analysis: twoOptions,
+			CubeAnalysis: &CubeAnalysis{CubefulNoDouble: 0.1, CubefulDoubleTake: -0.5, CubefulDoublePass: 1}},
+		{Type: MoveTypeDouble, Player: 1,
+			CubeAnalysis: &CubeAnalysis{CubefulNoDouble: 0.5, CubefulDoubleTake: 1.2, CubefulDoublePass: 1}},
+		{Type: MoveTypeTake, Player: 0,
+			CubeAnalysis: &CubeAnalysis{CubefulNoDouble: 0.5, CubefulDoubleTake: 1.2, CubefulDoublePass: 1},
+			Skill:        &SkillRating{Rating: "VeryBad", Error: 0.2}},
+	}}
+
+	perf := GamePerformance(&game, 0)
+	p0, p1 := perf.Players[0], perf.Players[1]
+
+	if p0.TotalMoves != 3 || p0.CheckerDecisions != 2 || p0.CubeDecisions != 1 {
+		t.Errorf("Player 0 decisions = %d/%d/%d, want 3/2/1", p0.TotalMoves, p0.CheckerDecisions, p0.CubeDecisions)
+	}
+	if p1.TotalMoves != 2 || p1.CheckerDecisions != 2 || p1.CubeDecisions != 2 {
+		t.Errorf("Player 1 decisions = %d/%d/%d, want 2/2/2", p1.TotalMoves, p1.CheckerDecisions, p1.CubeDecisions)
+	}
+	if p0.CheckerCounts.Bad != 1 || p0.CubeCounts.VeryBad != 1 || p0.WrongTakes != 1 {
+		t.Errorf("Player 0 counts = %+v / %+v, wrong takes %d", p0.CheckerCounts, p0.CubeCounts, p0.WrongTakes)
+	}
+	if p1.MissedDoubles != 1 || p1.WrongDoubles != 0 {
+		t.Errorf("Player 1 missed/wrong doubles = %d/%d, want 1/0", p1.MissedDoubles, p1.WrongDoubles)
+	}
+
+	checks := []struct {
+		name      string
+		got, want float64
+	}{
+		{"player 0 total error", p0.TotalError(), 0.3},
+		{"player 0 PR", p0.PR(), 500 * 0.3 / 3},
+		{"player 0 mEMG", p0.AverageErrorMilli(), 100},
+		{"player 0 checker PR", p0.CheckerPR(), 25},
+		{"player 1 cube PR", p1.CubePR(), 50},
+		{"player 0 Snowie rate", perf.SnowieErrorRate(0), 1000 * 0.3 / 5},
+	}
+	for _, c := range checks {
+		if math.Abs(c.got-c.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+
+	stat := computeGameStatistic(&game, 0, nil)
+	if stat.Moves.Unforced != [2]int{2, 2} || stat.Moves.Forced != [2]int{1, 0} {
+		t.Errorf("Moves unforced/forced = %v/%v", stat.Moves.Unforced, stat.Moves.Forced)
+	}
+	if stat.Cube.WrongTake != [2]int{1, 0} || stat.Cube.MissedDouble != [2]int{0, 1} {
+		t.Errorf("Cube wrong take/missed double = %v/%v", stat.Cube.WrongTake, stat.Cube.MissedDouble)
+	}
+	if !stat.HasMoves || !stat.HasCube || stat.HasDice {
+		t.Errorf("Has flags = %v/%v/%v, want true/true/false", stat.HasMoves, stat.HasCube, stat.HasDice)
+	}
+}
+
+func TestMatchPerformanceInFile(t *testing.T) {
+	match, err := ParseSGFFile("test/charlot1-charlot2_7p_2025-11-08-2305.sgf")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	perf := MatchPerformance(match)
+	for player, p := range perf.Players {
+		if p.UnforcedDecisions() == 0 {
+			t.Errorf("Player %d has no unforced decisions", player)
+		}
+		if p.PR() < 0 || perf.SnowieErrorRate(player) < 0 {
+			t.Errorf("Player %d has negative rates", player)
+		}
+	}
+
+	// Game statistics are filled when parsing
+	unforced := 0
+	for _, game := range match.Games {
+		unforced += game.Statistics.Moves.Unforced[0]
+	}
+	if unforced != perf.Players[0].CheckerDecisions {
+		t.Errorf("Game statistics count %d unforced plays, want %d", unforced, perf.Players[0].CheckerDecisions)
+	}
+}
+
+func TestRecordedGameStatistic(t *testing.T) {
+	match, err := ParseSGFFile("test/charlot1-charlot2_7p_2025-11-08-2305.sgf")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	// GS[M:21 21 22 23 ...][C:3 7 0 1 1 0 ...][D:0 0 0 0 22 23 0 0 0 0 -inf ...]
+	stat := match.Games[0].Statistics
+	if !stat.HasMoves || stat.Moves.Unforced != [2]int{21, 21} || stat.Moves.Forced != [2]int{1, 2} {
+		t.Errorf("Moves = %+v", stat.Moves)
+	}
+	if !stat.HasCube || stat.Cube.Unforced != [2]int{3, 7} {
+		t.Errorf("Cube = %+v", stat.Cube)
+	}
+	if !stat.HasDice || stat.Luck[0].None != 22 || stat.Luck[1].None != 23 {
+		t.Errorf("Luck = %+v", stat.Luck)
+	}
+
+	// Re-rating keeps the recorded sections
+	RateMatch(match, DefaultRatingOptions())
+	if got := match.Games[0].Statistics.Cube.Unforced; got != [2]int{3, 7} {
+		t.Errorf("Cube.Unforced after RateMatch = %v, want [3 7]", got)
+	}
+}
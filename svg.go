@@ -0,0 +1,65 @@
+package gnubgparser
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+)
+
+// RenderSVG writes the position as an SVG board diagram
+func (p *Position) RenderSVG(w io.Writer, opts DiagramOptions) error {
+	return layoutDiagram(p, opts).writeSVG(w)
+}
+
+// writeSVG writes the diagram as a standalone SVG document
+func (d *diagram) writeSVG(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g">`+"\n",
+		d.width, d.height, d.width, d.height)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" %s/>`+"\n", svgPaint("fill", d.background))
+
+	for _, s := range d.shapes {
+		switch s.kind {
+		case shapeRect:
+			a, b := s.pts[0], s.pts[1]
+			fmt.Fprintf(bw, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" %s%s/>`+"\n",
+				a.X, a.Y, b.X-a.X, b.Y-a.Y, svgPaint("fill", s.fill), svgPaint("stroke", s.stroke))
+		case shapePolygon:
+			fmt.Fprint(bw, `<polygon points="`)
+			for i, pt := range s.pts {
+				if i > 0 {
+					fmt.Fprint(bw, " ")
+				}
+				fmt.Fprintf(bw, "%.1f,%.1f", pt.X, pt.Y)
+			}
+			fmt.Fprintf(bw, `" %s/>`+"\n", svgPaint("fill", s.fill))
+		case shapeCircle:
+			fmt.Fprintf(bw, `<circle cx="%.1f" cy="%.1f" r="%.1f" %s%s/>`+"\n",
+				s.pts[0].X, s.pts[0].Y, s.size, svgPaint("fill", s.fill), svgPaint("stroke", s.stroke))
+		case shapeLine:
+			fmt.Fprintf(bw, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke-width="%.1f" %s/>`+"\n",
+				s.pts[0].X, s.pts[0].Y, s.pts[1].X, s.pts[1].Y, s.size, svgPaint("stroke", s.fill))
+		case shapeText:
+			anchor := [...]string{anchorStart: "start", anchorMiddle: "middle", anchorEnd: "end"}[s.anchor]
+			fmt.Fprintf(bw, `<text x="%.1f" y="%.1f" font-family="sans-serif" font-size="%.1f" text-anchor="%s" dominant-baseline="central" %s>%s</text>`+"\n",
+				s.pts[0].X, s.pts[0].Y, s.size, anchor, svgPaint("fill", s.fill), html.EscapeString(s.text))
+		}
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+// svgPaint returns a fill or stroke attribute with its opacity, or nothing
+// for a zero colour
+func svgPaint(attr string, c color.RGBA) string {
+	if c == (color.RGBA{}) {
+		return ""
+	}
+	s := fmt.Sprintf(`%s="#%02x%02x%02x" `, attr, c.R, c.G, c.B)
+	if c.A < 0xff {
+		s += fmt.Sprintf(`%s-opacity="%.2f" `, attr, float64(c.A)/0xff)
+	}
+	return s
+}
@@ -0,0 +1,222 @@
+package gnubgparser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteText writes a match in the layout of gnuBG's "export match text":
+// for each decision the board with its Position and Match IDs, the dice,
+// the action taken, the ranked analysed moves with equities and
+// probabilities, and the cube analysis
+func WriteText(w io.Writer, match *Match) error {
+	bw := bufio.NewWriter(w)
+	names := playerNames(match)
+	matchLength := match.Metadata.MatchLength
+
+	for g := range match.Games {
+		game := &match.Games[g]
+		writeTextGame(bw, g, game, names, matchLength, match.MET)
+	}
+	return bw.Flush()
+}
+
+// writeTextGame writes one game of the text export
+func writeTextGame(w *bufio.Writer, index int, game *Game, names [2]string, matchLength int, met *MatchEquityTable) {
+	kind := "money session"
+	if matchLength > 0 {
+		kind = fmt.Sprintf("match to %d points", matchLength)
+	}
+	fmt.Fprintf(w, "The score (after %d games) is: %s %d, %s %d (%s)\n\n",
+		index, names[0], game.Score[0], names[1], game.Score[1], kind)
+	fmt.Fprintf(w, "Game %d\n", index+1)
+	fmt.Fprintf(w, "%-35s %s : %d\n", fmt.Sprintf("%s : %d", names[0], game.Score[0]), names[1], game.Score[1])
+	if game.CrawfordGame {
+		fmt.Fprintln(w, "Crawford game")
+	}
+	fmt.Fprintln(w)
+
+	positions := GamePositions(game, matchLength)
+	checkers := checkersPerSide(game.Variation)
+
+	for i := range game.Moves {
+		mr := &game.Moves[i]
+		pos := positions[i]
+		if mr.Player != 0 && mr.Player != 1 {
+			continue
+		}
+		name := names[mr.Player]
+
+		state := matchIDState{Turn: mr.Player, Doubled: mr.Type == MoveTypeTake || mr.Type == MoveTypeDrop}
+		header, action, ok := textDecision(mr, &pos, name)
+		switch mr.Type {
+		case MoveTypeResign:
+			fmt.Fprintf(w, "* %s resigns", name)
+			if mr.ResignLevel > 0 {
+				fmt.Fprintf(w, " %s", resignLevelNames[mr.ResignLevel])
+			}
+			fmt.Fprint(w, "\n\n")
+			continue
+		case MoveTypeAccept, MoveTypeReject:
+			fmt.Fprintf(w, "* %s %ss the resignation\n\n", name, mr.Type)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		fmt.Fprintf(w, "Move number %d:  %s\n\n", i+1, header)
+		fmt.Fprintf(w, " GNU Backgammon  Position ID: %s\n", pos.PositionID())
+		fmt.Fprintf(w, "                 Match ID   : %s\n", pos.matchID(state))
+		fmt.Fprint(w, pos.RenderASCII(ASCIIOptions{Names: names, Checkers: checkers}))
+		fmt.Fprintln(w)
+
+		if mr.CubeAnalysis != nil {
+			writeTextCube(w, mr, game, matchLength, pos.CubeValue, met)
+		}
+		fmt.Fprintf(w, "* %s\n", action)
+		writeTextAlert(w, mr)
+		fmt.Fprintln(w)
+
+		if mr.Type == MoveTypeNormal && mr.Analysis != nil && len(mr.Analysis.Moves) > 0 {
+			writeTextMoves(w, mr, &pos, 0)
+		}
+		if mr.Comment != "" {
+			fmt.Fprintf(w, "%s\n\n", mr.Comment)
+		}
+	}
+
+	fmt.Fprintf(w, "* %s\n\n", gameResultText(game, names))
+}
+
+// textDecision returns the heading and the action line of a checker play
+// or cube action in the text export; ok is false for other records
+func textDecision(mr *MoveRecord, pos *Position, name string) (header, action string, ok bool) {
+	switch mr.Type {
+	case MoveTypeNormal:
+		header = fmt.Sprintf("%s to play %d%d", name, mr.Dice[0], mr.Dice[1])
+		played := *pos
+		action = fmt.Sprintf("%s moves %s", name, formatHops(played.applyMove(mr.Player, mr.Hops())))
+	case MoveTypeDouble:
+		header = fmt.Sprintf("%s doubles to %d", name, 2*pos.CubeValue)
+		action = fmt.Sprintf("%s doubles", name)
+	case MoveTypeTake:
+		header = name + " takes"
+		action = header
+	case MoveTypeDrop:
+		header = name + " passes"
+		action = header
+	default:
+		return "", "", false
+	}
+	return header, action, true
+}
+
+// writeTextAlert writes the alert line of a flagged checker play or cube
+// action
+func writeTextAlert(w *bufio.Writer, mr *MoveRecord) {
+	if mr.Skill != nil && severityRank(mr.Skill.Rating) > 0 {
+		what := map[MoveType]string{MoveTypeNormal: "move", MoveTypeDouble: "double",
+			MoveTypeTake: "take", MoveTypeDrop: "pass"}[mr.Type]
+		fmt.Fprintf(w, "Alert: %s %s (%+.3f)\n", textRating(mr.Skill.Rating), what, -mr.Skill.Error)
+	}
+}
+
+// writeTextCube writes the cube analysis block of a decision
+func writeTextCube(w *bufio.Writer, mr *MoveRecord, game *Game, matchLength, cube int, met *MatchEquityTable) {
+	holder := mr.Player
+	if mr.Type == MoveTypeTake || mr.Type == MoveTypeDrop {
+		holder = 1 - mr.Player
+	}
+	nd, dt, dp, ok := cubeEquities(mr, game, matchLength, holder, cube, met)
+	if !ok {
+		return
+	}
+	ca := mr.CubeAnalysis
+
+	fmt.Fprintln(w, "Cube analysis")
+	fmt.Fprintf(w, "%d-ply cubeless equity %+.3f\n", ca.AnalysisDepth, ca.CubelessEquity)
+	fmt.Fprintf(w, "  %.3f %.3f %.3f - %.3f %.3f %.3f\n",
+		ca.OnRollWin, ca.OnRollGammon, ca.OnRollBackgammon,
+		ca.OpponentWin, ca.OpponentGammon, ca.OpponentBackgammon)
+
+	type row struct {
+		action string
+		equity float64
+	}
+	rows := []row{{"No double", nd}, {"Double, take", dt}, {"Double, pass", dp}}
+	proper := properCubeAction(nd, dt, dp)
+	best := rows[proper.option()].action
+	sort.SliceStable(rows, func(a, b int) bool { return rows[a].equity > rows[b].equity })
+
+	fmt.Fprintln(w, "Cubeful equities:")
+	for i, r := range rows {
+		fmt.Fprintf(w, "%d. %-20s %+.3f", i+1, r.action, r.equity)
+		if r.action != best {
+			fmt.Fprintf(w, "  (%+.3f)", r.equity-proper.equity)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "Proper cube action: %s\n", proper)
+	if mr.Type == MoveTypeNormal && mr.CubeSkill != nil && severityRank(mr.CubeSkill.Rating) > 0 {
+		fmt.Fprintf(w, "Alert: missed double (%+.3f), %s\n", -mr.CubeSkill.Error, textRating(mr.CubeSkill.Rating))
+	}
+	fmt.Fprintln(w)
+}
+
+// writeTextMoves writes the ranked analysed moves of a checker play, the
+// played move marked with "*"; limit caps the number of moves (0 for all),
+// though the played move is always listed
+func writeTextMoves(w *bufio.Writer, mr *MoveRecord, pos *Position, limit int) {
+	fmt.Fprintf(w, "Rolled %d%d", mr.Dice[0], mr.Dice[1])
+	if mr.Luck != nil {
+		fmt.Fprintf(w, " (%+.3f)", mr.Luck.Value)
+	}
+	fmt.Fprintln(w, ":")
+
+	best := mr.Analysis.Moves[0].Equity
+	for i, opt := range mr.Analysis.Moves {
+		if limit > 0 && i >= limit && i != mr.Analysis.SelectedMove {
+			continue
+		}
+		mark := " "
+		if i == mr.Analysis.SelectedMove {
+			mark = "*"
+		}
+		move := optionNotation(&opt, pos, mr.Player)
+
+		fmt.Fprintf(w, "%s %4d. Cubeful %d-ply    %-28s Eq.: %+.3f", mark, i+1, opt.AnalysisDepth, move, opt.Equity)
+		if i > 0 {
+			fmt.Fprintf(w, " (%+.3f)", opt.Equity-best)
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "       %.3f %.3f %.3f - %.3f %.3f %.3f\n",
+			opt.OnRollWin, opt.OnRollGammon, opt.OnRollBackgammon,
+			opt.OpponentWin, opt.OpponentGammon, opt.OpponentBackgammon)
+	}
+	fmt.Fprintln(w)
+}
+
+// optionNotation writes an analysed move in the usual notation, played
+// from pos
+func optionNotation(opt *MoveOption, pos *Position, player int) string {
+	if opt.Move[0] < 0 && opt.MoveString != "" {
+		return opt.MoveString
+	}
+	after := *pos
+	return formatHops(after.applyMove(player, moveHops(opt.Move, nil, player)))
+}
+
+// textRating spells a skill or luck rating in lower case words
+func textRating(rating string) string {
+	switch rating {
+	case "VeryBad":
+		return "very bad"
+	case "VeryGood":
+		return "very good"
+	}
+	return strings.ToLower(rating)
+}
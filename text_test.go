@@ -0,0 +1,39 @@
+package gnubgparser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	match, err := ParseSGFFile("test/charlot1-charlot2_7p_2025-11-08-2305.sgf")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteText(&buf, match); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"The score (after 0 games) is: charlot1 0, charlot2 0 (match to 7 points)",
+		"Move number 1:  charlot2 to play 41",
+		" GNU Backgammon  Position ID: 4HPwATDgc/ABMA",
+		" +13-14-15-16-17-18------19-20-21-22-23-24-+     O: charlot2",
+		" +12-11-10--9--8--7-------6--5--4--3--2--1-+     X: charlot1",
+		"Pip count  O: 167  X: 167",
+		"* charlot2 moves 24/23 13/9",
+		"*    1. Cubeful 0-ply    24/23 13/9",
+		"Move number 19:  charlot2 doubles to 2",
+		"Proper cube action: Double, take",
+		"* charlot1 takes",
+		"* charlot1 wins 4 points (gammon)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Text export does not contain %q", want)
+		}
+	}
+}
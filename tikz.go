@@ -0,0 +1,185 @@
+package gnubgparser
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// RenderTikZ writes the position as a LaTeX tikzpicture of the board
+// diagram of RenderSVG. A pixel of the diagram is half a point, so the
+// default 600 pixel width gives a 300pt wide board; the document needs
+// \usepackage{tikz}.
+func (p *Position) RenderTikZ(w io.Writer, opts DiagramOptions) error {
+	bw := bufio.NewWriter(w)
+	layoutDiagram(p, opts).writeTikZ(bw)
+	return bw.Flush()
+}
+
+// writeTikZ writes the diagram as a tikzpicture, y pointing down as in the
+// image renderers
+func (d *diagram) writeTikZ(w *bufio.Writer) {
+	const pt = 0.5 // Points per pixel
+	fmt.Fprintf(w, "\\begin{tikzpicture}[x=%gpt, y=%gpt, yscale=-1]\n", pt, pt)
+	fmt.Fprintf(w, "\\fill[%s] (0,0) rectangle (%g,%g);\n", tikzColor("fill", d.background), d.width, d.height)
+
+	for _, s := range d.shapes {
+		cmd, style := `\fill`, tikzColor("fill", s.fill)
+		if s.stroke.A > 0 {
+			cmd = `\filldraw`
+			style += ", " + tikzColor("draw", s.stroke) + fmt.Sprintf(", line width=%.2fpt", pt)
+		}
+		switch s.kind {
+		case shapeRect:
+			fmt.Fprintf(w, "%s[%s] (%.1f,%.1f) rectangle (%.1f,%.1f);\n",
+				cmd, style, s.pts[0].X, s.pts[0].Y, s.pts[1].X, s.pts[1].Y)
+		case shapePolygon:
+			fmt.Fprintf(w, "%s[%s] ", cmd, style)
+			for _, pt := range s.pts {
+				fmt.Fprintf(w, "(%.1f,%.1f) -- ", pt.X, pt.Y)
+			}
+			fmt.Fprintln(w, "cycle;")
+		case shapeCircle:
+			fmt.Fprintf(w, "%s[%s] (%.1f,%.1f) circle[radius=%.1f];\n", cmd, style, s.pts[0].X, s.pts[0].Y, s.size)
+		case shapeLine:
+			fmt.Fprintf(w, "\\draw[%s, line width=%.2fpt] (%.1f,%.1f) -- (%.1f,%.1f);\n",
+				tikzColor("draw", s.fill), s.size*pt, s.pts[0].X, s.pts[0].Y, s.pts[1].X, s.pts[1].Y)
+		case shapeText:
+			anchor := [...]string{anchorStart: "west", anchorMiddle: "center", anchorEnd: "east"}[s.anchor]
+			size := s.size * pt
+			fmt.Fprintf(w, "\\node[%s, anchor=%s, inner sep=0, font=\\fontsize{%.1f}{%.1f}\\selectfont\\sffamily] at (%.1f,%.1f) {%s};\n",
+				tikzColor("text", s.fill), anchor, size, size, s.pts[0].X, s.pts[0].Y, latexEscape(s.text))
+		}
+	}
+	fmt.Fprintln(w, "\\end{tikzpicture}")
+}
+
+// tikzColor returns a TikZ colour option, with its opacity when the colour
+// is translucent
+func tikzColor(key string, c color.RGBA) string {
+	s := fmt.Sprintf("%s={rgb,255:red,%d;green,%d;blue,%d}", key, c.R, c.G, c.B)
+	if c.A < 0xff && key != "text" {
+		s += fmt.Sprintf(", %s opacity=%.2f", key, float64(c.A)/0xff)
+	}
+	return s
+}
+
+// latexEscape escapes the characters LaTeX treats specially
+var latexEscape = strings.NewReplacer(
+	`\`, `\textbackslash{}`, `{`, `\{`, `}`, `\}`, `$`, `\$`, `&`, `\&`, `#`, `\#`,
+	`%`, `\%`, `_`, `\_`, `^`, `\textasciicircum{}`, `~`, `\textasciitilde{}`,
+).Replace
+
+// LaTeXOptions controls WriteLaTeX
+type LaTeXOptions struct {
+	// Board options; Names, Checkers and Move are set for each decision
+	Diagram DiagramOptions
+	// Only decisions rated at least this badly: "Doubtful", "Bad" or
+	// "VeryBad"; empty for every checker play and cube action
+	MinRating string
+	// Write only the figures, to \input into a document that loads tikz
+	Fragment bool
+}
+
+// WriteLaTeX writes decisions of a match as LaTeX figures, each with a TikZ
+// board showing the score, cube and dice with the move played drawn as
+// arrows, and a caption giving the game and move, the action played and the
+// best one with their equities from the analysis. Unless opts.Fragment is
+// set the figures are wrapped in a complete article.
+func WriteLaTeX(w io.Writer, match *Match, opts LaTeXOptions) error {
+	bw := bufio.NewWriter(w)
+	names := playerNames(match)
+	matchLength := match.Metadata.MatchLength
+
+	if !opts.Fragment {
+		fmt.Fprintln(bw, "\\documentclass{article}")
+		fmt.Fprintln(bw, "\\usepackage[T1]{fontenc}")
+		fmt.Fprintln(bw, "\\usepackage{lmodern}")
+		fmt.Fprintln(bw, "\\usepackage{tikz}")
+		fmt.Fprintln(bw, "\\begin{document}")
+		fmt.Fprintf(bw, "\\section*{%s vs %s}\n\n", latexEscape(names[0]), latexEscape(names[1]))
+	}
+
+	diagram := opts.Diagram
+	diagram.Names = names
+	figures := 0
+	for g := range match.Games {
+		game := &match.Games[g]
+		positions := GamePositions(game, matchLength)
+		diagram.Checkers = checkersPerSide(game.Variation)
+
+		for i := range game.Moves {
+			mr := &game.Moves[i]
+			pos := positions[i]
+			if mr.Player != 0 && mr.Player != 1 {
+				continue
+			}
+			header, _, ok := textDecision(mr, &pos, names[mr.Player])
+			if !ok || severityRank(worstRating(mr)) < severityRank(opts.MinRating) {
+				continue
+			}
+
+			diagram.Move = mr.Hops()
+			fmt.Fprintln(bw, "\\begin{figure}[htbp]")
+			fmt.Fprintln(bw, "\\centering")
+			layoutDiagram(&pos, diagram).writeTikZ(bw)
+			fmt.Fprintf(bw, "\\caption{Game %d, move %d: %s. %s}\n", g+1, i+1, latexEscape(header),
+				latexEscape(latexCaption(mr, game, &pos, matchLength, match.MET)))
+			fmt.Fprint(bw, "\\end{figure}\n\n")
+
+			// Two boards fill a page; flush them before the float queue
+			// overflows
+			if figures++; figures%2 == 0 && !opts.Fragment {
+				fmt.Fprint(bw, "\\clearpage\n\n")
+			}
+		}
+	}
+
+	if !opts.Fragment {
+		fmt.Fprintln(bw, "\\end{document}")
+	}
+	return bw.Flush()
+}
+
+// latexCaption describes the action played and the best one of a decision,
+// with their equities when the record was analysed
+func latexCaption(mr *MoveRecord, game *Game, pos *Position, matchLength int, met *MatchEquityTable) string {
+	var parts []string
+	if mr.Type == MoveTypeNormal {
+		after := *pos
+		played := "Played " + formatHops(after.applyMove(mr.Player, mr.Hops()))
+		if a := mr.Analysis; a != nil && len(a.Moves) > 0 {
+			if a.PlayedKnown() {
+				played += fmt.Sprintf(" (%+.3f)", a.Moves[a.SelectedMove].Equity)
+			}
+			parts = append(parts, played)
+			if a.SelectedMove != 0 {
+				parts = append(parts, fmt.Sprintf("Best %s (%+.3f)", optionNotation(&a.Moves[0], pos, mr.Player), a.Moves[0].Equity))
+			}
+		} else {
+			parts = append(parts, played)
+		}
+	} else {
+		holder := mr.Player
+		if mr.Type == MoveTypeTake || mr.Type == MoveTypeDrop {
+			holder = 1 - mr.Player
+		}
+		if nd, dt, dp, ok := cubeEquities(mr, game, matchLength, holder, pos.CubeValue, met); ok {
+			proper := properCubeAction(nd, dt, dp)
+			parts = append(parts, fmt.Sprintf("No double %+.3f, double/take %+.3f, double/pass %+.3f",
+				nd, dt, dp), fmt.Sprintf("Proper cube action: %s (%+.3f)", proper, proper.equity))
+		}
+	}
+	if mr.Skill != nil && severityRank(mr.Skill.Rating) > 0 {
+		parts = append(parts, fmt.Sprintf("Error %.3f, %s", mr.Skill.Error, textRating(mr.Skill.Rating)))
+	}
+	if mr.CubeSkill != nil && severityRank(mr.CubeSkill.Rating) > 0 {
+		parts = append(parts, fmt.Sprintf("Missed double %.3f, %s", mr.CubeSkill.Error, textRating(mr.CubeSkill.Rating)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, ". ") + "."
+}
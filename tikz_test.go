@@ -0,0 +1,80 @@
+package gnubgparser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRenderTikZ(t *testing.T) {
+	pos := Position{Board: startingBoard("Standard"), CubeValue: 1, CubeOwner: -1}
+	var buf bytes.Buffer
+	if err := pos.RenderTikZ(&buf, DiagramOptions{Names: [2]string{"A&B_1", "50% Bob"}}); err != nil {
+		t.Fatalf("RenderTikZ failed: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(out, `\begin{tikzpicture}`) || !strings.HasSuffix(out, "\\end{tikzpicture}\n") {
+		t.Errorf("Output is not a tikzpicture:\n%s", out)
+	}
+	if n := strings.Count(out, "circle[radius="); n != 30 {
+		t.Errorf("Got %d checkers, want 30", n)
+	}
+	for _, want := range []string{`{A\&B\_1: 0}`, `{50\% Bob: 0}`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output does not contain %q", want)
+		}
+	}
+}
+
+func TestWriteLaTeX(t *testing.T) {
+	match := &Match{
+		Metadata: MatchMetadata{Player1: "Alice", Player2: "Bob"},
+		Games: []Game{{Winner: 1, Points: 1, Moves: []MoveRecord{
+			{Type: MoveTypeNormal, Player: 0, Dice: [2]int{3, 1}, SubMoves: []SubMove{{From: 7, To: 4}, {From: 5, To: 4}}},
+			{Type: MoveTypeNormal, Player: 1, Dice: [2]int{6, 5}, SubMoves: []SubMove{{From: 23, To: 18}, {From: 18, To: 12}},
+				Skill: &SkillRating{Rating: "Bad", Error: 0.09},
+				Analysis: &MoveAnalysis{SelectedMove: 1, Moves: []MoveOption{
+					{Move: [8]int{-1}, MoveString: "24/18 13/8", Equity: 0.05},
+					{Move: [8]int{-1}, MoveString: "24/13", Equity: -0.04},
+				}}},
+		}}},
+	}
+
+	tests := []struct {
+		name    string
+		opts    LaTeXOptions
+		figures int
+		want    []string
+		notWant []string
+	}{
+		{"all decisions", LaTeXOptions{}, 2,
+			[]string{`\documentclass{article}`, `\caption{Game 1, move 1: Alice to play 31. Played 8/5 6/5.}`, `\end{document}`}, nil},
+		{"errors only", LaTeXOptions{MinRating: "Bad", Fragment: true}, 1,
+			[]string{`\caption{Game 1, move 2: Bob to play 65. Played 24/13 (-0.040). Best 24/18 13/8 (+0.050). Error 0.090, bad.}`},
+			[]string{`\documentclass`, `\end{document}`}},
+		{"none bad enough", LaTeXOptions{MinRating: "VeryBad", Fragment: true}, 0, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteLaTeX(&buf, match, tt.opts); err != nil {
+				t.Fatalf("WriteLaTeX failed: %v", err)
+			}
+			out := buf.String()
+			if n := strings.Count(out, `\begin{figure}`); n != tt.figures {
+				t.Errorf("Got %d figures, want %d", n, tt.figures)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("Output does not contain %q", want)
+				}
+			}
+			for _, bad := range tt.notWant {
+				if strings.Contains(out, bad) {
+					t.Errorf("Output contains %q", bad)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package gnubgparser
+
+import "math"
+
+// Decision kinds of a timeline point
+const (
+	DecisionChecker = "checker"
+	DecisionCube    = "cube"
+)
+
+// TimelinePoint is the evaluation of one analysed decision of a match.
+// Probabilities and equities are those of the best option: the best move
+// for a checker play, the best cube action for a cube decision.
+type TimelinePoint struct {
+	Game     int       `json:"game"`     // Index in Games
+	Move     int       `json:"move"`     // Index in the game's Moves
+	Type     MoveType  `json:"type"`     // Type of the move record
+	Decision string    `json:"decision"` // DecisionChecker or DecisionCube
+	Player   int       `json:"player"`   // Player who made the decision
+	OnRoll   int       `json:"on_roll"`  // Player the evaluation refers to (the doubler for take and drop)
+	Score    [2]int    `json:"score"`    // Score before the game
+	Cube     CubeState `json:"cube"`
+	// Cubeless win probability of the player on roll and of each player
+	OnRollWin float64    `json:"on_roll_win"`
+	Win       [2]float64 `json:"win"`
+	// Cubeful equity in EMG for the player on roll, normalized to the cube
+	Equity float64 `json:"equity"`
+	// Match winning chances of each player (match play only)
+	MWC [2]float64 `json:"mwc,omitempty"`
+}
+
+// TimelineScore is the score after a game
+type TimelineScore struct {
+	Game  int        `json:"game"`          // Index in Games
+	Score [2]int     `json:"score"`         // Score after the game
+	MWC   [2]float64 `json:"mwc,omitempty"` // Match winning chances (match play only)
+}
+
+// Timeline is the evolution of a match, as series suitable for charting
+type Timeline struct {
+	Points []TimelinePoint `json:"points"`
+	Scores []TimelineScore `json:"scores"`
+}
+
+// Timeline returns a point for every analysed decision of the match and
+// the score after each game. A normal move with both a cube and a checker
+// analysis gives two points, the cube decision first.
+func (m *Match) Timeline() Timeline {
+	var tl Timeline
+	matchLength := m.Metadata.MatchLength
+	var score [2]int
+	crawfordDone := false
+
+	for g := range m.Games {
+		game := &m.Games[g]
+		cubes, _, _ := replayCube(game)
+
+		for i := range game.Moves {
+			mr := &game.Moves[i]
+			if mr.Player < 0 || mr.Player > 1 {
+				continue
+			}
+			switch mr.Type {
+			case MoveTypeNormal, MoveTypeDouble, MoveTypeTake, MoveTypeDrop:
+			default:
+				continue
+			}
+			point := TimelinePoint{
+				Game:   g,
+				Move:   i,
+				Type:   mr.Type,
+				Player: mr.Player,
+				OnRoll: mr.Player,
+				Score:  game.Score,
+				Cube:   cubes[i],
+			}
+
+			if mr.Type == MoveTypeTake || mr.Type == MoveTypeDrop {
+				point.OnRoll = 1 - mr.Player
+			}
+
+			if nd, dt, dp, ok := cubeEquities(mr, game, matchLength, point.OnRoll, cubes[i].Value, m.MET); ok {
+				p := point
+				p.Decision = DecisionCube
+				p.OnRollWin = float64(mr.CubeAnalysis.OnRollWin)
+				p.Equity = math.Max(nd, math.Min(dt, dp))
+				p.fill(game, matchLength, m.MET)
+				tl.Points = append(tl.Points, p)
+			}
+
+			if mr.Type == MoveTypeNormal && mr.Analysis != nil && len(mr.Analysis.Moves) > 0 {
+				best := mr.Analysis.Moves[0]
+				p := point
+				p.Decision = DecisionChecker
+				p.OnRollWin = float64(best.OnRollWin)
+				p.Equity = best.Equity
+				p.fill(game, matchLength, m.MET)
+				tl.Points = append(tl.Points, p)
+			}
+		}
+
+		// Match play games carry the score before them; money sessions
+		// keep a running total
+		if matchLength > 0 {
+			score = game.Score
+		}
+		if game.Winner == 0 || game.Winner == 1 {
+			score[game.Winner] += game.Points
+		}
+		crawfordDone = crawfordDone || game.CrawfordGame || game.PostCrawford
+
+		entry := TimelineScore{Game: g, Score: score}
+		if matchLength > 0 {
+			entry.MWC[0] = m.MET.equityAt(score, matchLength, crawfordDone, 0)
+			entry.MWC[1] = 1 - entry.MWC[0]
+		}
+		tl.Scores = append(tl.Scores, entry)
+	}
+
+	return tl
+}
+
+// fill derives the absolute win probabilities and match winning chances
+// of a point from its on-roll values
+func (p *TimelinePoint) fill(game *Game, matchLength int, met *MatchEquityTable) {
+	p.Win[p.OnRoll] = p.OnRollWin
+	p.Win[1-p.OnRoll] = 1 - p.OnRollWin
+
+	if matchLength > 0 {
+		mwc := met.emgToMWC(p.Equity, game, matchLength, p.OnRoll, p.Cube.Value)
+		p.MWC[p.OnRoll] = mwc
+		p.MWC[1-p.OnRoll] = 1 - mwc
+	}
+}
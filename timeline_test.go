@@ -0,0 +1,73 @@
+package gnubgparser
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMatchTimeline(t *testing.T) {
+	match, err := ParseSGFFile("test/charlot1-charlot2_7p_2025-11-08-2305.sgf")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	tl := match.Timeline()
+	if len(tl.Scores) != len(match.Games) {
+		t.Fatalf("Scores = %d, want %d", len(tl.Scores), len(match.Games))
+	}
+	last := tl.Scores[len(tl.Scores)-1]
+	if last.Score != [2]int{9, 2} || last.MWC != [2]float64{1, 0} {
+		t.Errorf("Final score = %v with MWC %v, want [9 2] with [1 0]", last.Score, last.MWC)
+	}
+
+	if len(tl.Points) == 0 {
+		t.Fatal("Timeline has no points")
+	}
+	for _, p := range tl.Points {
+		if math.Abs(p.Win[0]+p.Win[1]-1) > 1e-6 || math.Abs(p.MWC[0]+p.MWC[1]-1) > 1e-9 {
+			t.Errorf("Game %d move %d: win %v, MWC %v do not sum to 1", p.Game, p.Move, p.Win, p.MWC)
+		}
+		if p.MWC[0] < 0 || p.MWC[0] > 1 {
+			t.Errorf("Game %d move %d: MWC %v out of range", p.Game, p.Move, p.MWC)
+		}
+		if p.Win[p.OnRoll] != p.OnRollWin {
+			t.Errorf("Game %d move %d: on-roll win %v, absolute %v", p.Game, p.Move, p.OnRollWin, p.Win)
+		}
+		if p.Type == MoveTypeTake && p.OnRoll == p.Player {
+			t.Errorf("Game %d move %d: take evaluated for the taker", p.Game, p.Move)
+		}
+	}
+}
+
+func TestMoneyTimeline(t *testing.T) {
+	match := &Match{Games: []Game{
+		{Winner: 0, Points: 2, Moves: []MoveRecord{
+			{Type: MoveTypeNormal, Player: 0, Analysis: &MoveAnalysis{Moves: []MoveOption{{Equity: 0.3, OnRollWin: 0.6}}}},
+			{Type: MoveTypeDouble, Player: 1,
+				CubeAnalysis: &CubeAnalysis{OnRollWin: 0.7, CubefulNoDouble: 0.6, CubefulDoubleTake: 0.9, CubefulDoublePass: 1}},
+			{Type: MoveTypeTake, Player: 0,
+				CubeAnalysis: &CubeAnalysis{OnRollWin: 0.7, CubefulNoDouble: 0.6, CubefulDoubleTake: 0.9, CubefulDoublePass: 1}},
+		}},
+		{Winner: 1, Points: 1},
+	}}
+
+	tl := match.Timeline()
+	if len(tl.Points) != 3 {
+		t.Fatalf("Points = %d, want 3", len(tl.Points))
+	}
+
+	checker, take := tl.Points[0], tl.Points[2]
+	if checker.Decision != DecisionChecker || math.Abs(checker.Win[0]-0.6) > 1e-6 || checker.Equity != 0.3 {
+		t.Errorf("Checker point = %+v", checker)
+	}
+	if take.Decision != DecisionCube || take.OnRoll != 1 || take.Equity != 0.9 || math.Abs(take.Win[1]-0.7) > 1e-6 {
+		t.Errorf("Take point = %+v", take)
+	}
+	if take.MWC != [2]float64{} {
+		t.Errorf("Money play MWC = %v, want none", take.MWC)
+	}
+
+	if tl.Scores[0].Score != [2]int{2, 0} || tl.Scores[1].Score != [2]int{2, 1} {
+		t.Errorf("Scores = %+v, want running totals 2-0 and 2-1", tl.Scores)
+	}
+}
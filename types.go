@@ -16,6 +16,13 @@ type Match struct {
 	Metadata MatchMetadata `json:"metadata"`
 	// List of games in the match
 	Games []Game `json:"games"`
+	// Producing program detected for MAT files (empty for SGF)
+	Dialect MATDialect `json:"dialect,omitempty"`
+	// Non-fatal problems found while parsing (e.g., unparseable move tokens)
+	Warnings []string `json:"warnings,omitempty"`
+	// Match equity table converting match play equities; nil is
+	// DefaultMET. Call RateMatch after changing it.
+	MET *MatchEquityTable `json:"-"`
 }
 
 // MatchMetadata contains information about the match
@@ -36,6 +43,12 @@ type MatchMetadata struct {
 	Comment     string `json:"comment,omitempty"`
 	// SGF metadata
 	Application string `json:"application,omitempty"` // e.g., "GNU Backgammon:1.06.002"
+	// Extended MAT header metadata (XG, BGBlitz)
+	EventTime string            `json:"event_time,omitempty"`
+	Beaver    bool              `json:"beaver,omitempty"`     // Beavers allowed
+	CubeLimit int               `json:"cube_limit,omitempty"` // Maximum cube value (0 = unlimited)
+	Unrated   bool              `json:"unrated,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"` // Unrecognised header tags, by tag name
 }
 
 // Game represents a single game within a match
@@ -45,15 +58,19 @@ type Game struct {
 	Variation    string        `json:"variation"`     // "Standard", "Nackgammon", "Hypergammon1", etc.
 	Crawford     bool          `json:"crawford"`      // Is Crawford rule in effect?
 	CrawfordGame bool          `json:"crawford_game"` // Is this the Crawford game?
+	PostCrawford bool          `json:"post_crawford"` // Is this game after the Crawford game?
 	Jacoby       bool          `json:"jacoby"`        // Jacoby rule (money games)
 	CubeEnabled  bool          `json:"cube_enabled"`  // Is cube enabled?
 	AutoDoubles  int           `json:"auto_doubles"`  // Number of automatic doubles
 	Winner       int           `json:"winner"`        // Winner (0=player1, 1=player2, -1=not finished)
 	Points       int           `json:"points"`        // Points won
 	Resigned     bool          `json:"resigned"`      // Was the game resigned?
+	ResultKind   ResultKind    `json:"result_kind,omitempty"`
 	Moves        []MoveRecord  `json:"moves"`
 	GameComment  string        `json:"comment,omitempty"`
 	Statistics   GameStatistic `json:"statistics,omitempty"`
+
+	recorded *recordedStatistic // gnuBG's GS property, if any
 }
 
 // MoveRecord represents a single move, cube decision, or game event
@@ -62,17 +79,30 @@ type MoveRecord struct {
 	Player       int           `json:"player"` // 0 or 1
 	Dice         [2]int        `json:"dice,omitempty"`
 	Move         [8]int        `json:"move,omitempty"`          // Encoded move (gnuBG format)
+	SubMoves     []SubMove     `json:"submoves,omitempty"`      // Individual hops with hit markers (MAT)
 	MoveString   string        `json:"move_string,omitempty"`   // Human-readable move
 	CubeValue    int           `json:"cube_value,omitempty"`    // For SETCUBEVAL
 	CubeOwner    int           `json:"cube_owner,omitempty"`    // For SETCUBEPOS (-1=center, 0=p1, 1=p2)
+	Cube         CubeState     `json:"cube"`                    // Cube in effect before this record
+	ResignLevel  int           `json:"resign_level,omitempty"`  // For RESIGN: 1=single, 2=gammon, 3=backgammon (0=unknown)
 	Position     *Position     `json:"position,omitempty"`      // For SETBOARD
 	Analysis     *MoveAnalysis `json:"analysis,omitempty"`      // Move analysis
 	CubeAnalysis *CubeAnalysis `json:"cube_analysis,omitempty"` // Cube decision analysis
 	Luck         *LuckRating   `json:"luck,omitempty"`
-	Skill        *SkillRating  `json:"skill,omitempty"`
+	Skill        *SkillRating  `json:"skill,omitempty"`      // Checker play, or the cube action of DOUBLE/TAKE/DROP
+	CubeSkill    *SkillRating  `json:"cube_skill,omitempty"` // Decision not to double before a checker play
 	Comment      string        `json:"comment,omitempty"`
 }
 
+// SubMove represents a single checker hop within a move.
+// Points use the MAT internal convention: 0-23 for points 1-24,
+// 24 for the bar and -1 for off.
+type SubMove struct {
+	From int  `json:"from"`
+	To   int  `json:"to"`
+	Hit  bool `json:"hit,omitempty"` // A blot was hit on the landing point
+}
+
 // MoveType represents the type of move record
 type MoveType string
 
@@ -81,17 +111,30 @@ const (
 	MoveTypeDouble     MoveType = "double"     // Cube doubled
 	MoveTypeTake       MoveType = "take"       // Double taken
 	MoveTypeDrop       MoveType = "drop"       // Double dropped/passed
-	MoveTypeResign     MoveType = "resign"     // Resignation
+	MoveTypeResign     MoveType = "resign"     // Resignation offered
+	MoveTypeAccept     MoveType = "accept"     // Resignation accepted
+	MoveTypeReject     MoveType = "reject"     // Resignation rejected
 	MoveTypeSetBoard   MoveType = "setboard"   // Set board position
 	MoveTypeSetDice    MoveType = "setdice"    // Set dice (for positions)
 	MoveTypeSetCube    MoveType = "setcube"    // Set cube value
 	MoveTypeSetCubePos MoveType = "setcubepos" // Set cube owner
 )
 
+// ResultKind classifies how a game ended
+type ResultKind string
+
+const (
+	ResultSingle        ResultKind = "single"         // Single game won by bearing off
+	ResultGammon        ResultKind = "gammon"         // Gammon won by bearing off
+	ResultBackgammon    ResultKind = "backgammon"     // Backgammon won by bearing off
+	ResultDroppedDouble ResultKind = "dropped_double" // Double was dropped
+	ResultResignation   ResultKind = "resignation"    // Loser resigned
+)
+
 // Position represents a backgammon board position
 type Position struct {
 	// Board[0] is player 0's checkers, Board[1] is player 1's checkers
-	// Index 0-23 are points 1-24, index 24 is the bar
+	// Index 0-23 are points 1-24 from that player's side, index 24 is the bar
 	Board       [2][25]int `json:"board"`
 	CubeValue   int        `json:"cube_value"`
 	CubeOwner   int        `json:"cube_owner"` // -1=center, 0=player1, 1=player2
@@ -106,25 +149,57 @@ type Position struct {
 type MoveAnalysis struct {
 	// Top moves evaluated
 	Moves []MoveOption `json:"moves"`
-	// Selected move index (in Moves array)
+	// Selected move index (in Moves array), or SelectedMoveUnknown if the
+	// played move is not listed
 	SelectedMove int `json:"selected_move"`
+	// Equity lost by the played move relative to the best option; only
+	// meaningful when PlayedKnown reports true
+	EquityLoss float64 `json:"equity_loss"`
+}
+
+// SelectedMoveUnknown is the SelectedMove of an analysis that does not list
+// the played move, whose equity loss is then unknown
+const SelectedMoveUnknown = -1
+
+// PlayedKnown reports whether the played move is among the analysed
+// options, so that EquityLoss holds its error
+func (a *MoveAnalysis) PlayedKnown() bool {
+	return a.SelectedMove >= 0 && a.SelectedMove < len(a.Moves)
 }
 
-// MoveOption represents one possible move with evaluation
+// MoveOption represents one possible move with evaluation.
+//
+// gnuBG evaluates from the point of view of the player on roll (the player
+// making the move). The OnRoll and Opponent fields keep that view; the
+// Player1 and Player2 fields hold the same probabilities in absolute
+// player slots, so Player1 fields always describe player 0 (Player1).
 type MoveOption struct {
 	Move                  [8]int  `json:"move"`        // Encoded move
 	MoveString            string  `json:"move_string"` // Human-readable
-	Equity                float64 `json:"equity"`      // Equity of position after this move
+	Equity                float64 `json:"equity"`      // Equity of position after this move, for the player on roll
 	Player1WinRate        float32 `json:"player1_win_rate"`
 	Player1GammonRate     float32 `json:"player1_gammon_rate"`
 	Player1BackgammonRate float32 `json:"player1_backgammon_rate"`
 	Player2WinRate        float32 `json:"player2_win_rate"`
 	Player2GammonRate     float32 `json:"player2_gammon_rate"`
 	Player2BackgammonRate float32 `json:"player2_backgammon_rate"`
-	AnalysisDepth         int     `json:"analysis_depth"` // Ply depth (0=book)
+	// Probabilities from the point of view of the player on roll
+	OnRollWin          float32 `json:"on_roll_win"`
+	OnRollGammon       float32 `json:"on_roll_gammon"`
+	OnRollBackgammon   float32 `json:"on_roll_backgammon"`
+	OpponentWin        float32 `json:"opponent_win"`
+	OpponentGammon     float32 `json:"opponent_gammon"`
+	OpponentBackgammon float32 `json:"opponent_backgammon"`
+	AnalysisDepth      int     `json:"analysis_depth"` // Ply depth (0=book)
 }
 
-// CubeAnalysis contains analysis for cube decisions
+// CubeAnalysis contains analysis for cube decisions.
+//
+// gnuBG evaluates cube decisions from the point of view of the player
+// holding the decision: the player on roll for no double and double
+// records, the doubler for take and drop records. The OnRoll fields refer
+// to that player; Player1 and Player2 fields use absolute player slots.
+// Equities are always from the doubler's point of view.
 type CubeAnalysis struct {
 	Player1WinRate        float32 `json:"player1_win_rate"`
 	Player1GammonRate     float32 `json:"player1_gammon_rate"`
@@ -132,6 +207,13 @@ type CubeAnalysis struct {
 	Player2WinRate        float32 `json:"player2_win_rate"`
 	Player2GammonRate     float32 `json:"player2_gammon_rate"`
 	Player2BackgammonRate float32 `json:"player2_backgammon_rate"`
+	// Probabilities from the point of view of the player on roll
+	OnRollWin          float32 `json:"on_roll_win"`
+	OnRollGammon       float32 `json:"on_roll_gammon"`
+	OnRollBackgammon   float32 `json:"on_roll_backgammon"`
+	OpponentWin        float32 `json:"opponent_win"`
+	OpponentGammon     float32 `json:"opponent_gammon"`
+	OpponentBackgammon float32 `json:"opponent_backgammon"`
 	// Cube equities
 	CubelessEquity    float64 `json:"cubeless_equity"`
 	CubefulNoDouble   float64 `json:"cubeful_no_double"`
@@ -148,12 +230,16 @@ type CubeAnalysis struct {
 type LuckRating struct {
 	Rating string  `json:"rating"` // "VeryBad", "Bad", "None", "Good", "VeryGood"
 	Value  float64 `json:"value"`  // Luck value (equity change due to roll)
+	// Rating derived from Value rather than read from the file
+	Derived bool `json:"derived,omitempty"`
 }
 
 // SkillRating represents skill analysis for a decision
 type SkillRating struct {
 	Rating string  `json:"rating"` // "VeryBad", "Bad", "Doubtful", "None"
 	Error  float64 `json:"error"`  // Error in equity
+	// Rating derived from the analysis rather than read from the file
+	Derived bool `json:"derived,omitempty"`
 }
 
 // GameStatistic contains statistics for a game
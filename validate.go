@@ -0,0 +1,137 @@
+package gnubgparser
+
+import "fmt"
+
+// ValidationIssue describes an inconsistency found by Match.Validate
+type ValidationIssue struct {
+	Game    int    `json:"game"` // Index in Games (-1 = whole match)
+	Move    int    `json:"move"` // Index in the game's Moves (-1 = whole game)
+	Message string `json:"message"`
+}
+
+// String formats the issue with 1-based game and move numbers
+func (i ValidationIssue) String() string {
+	switch {
+	case i.Game < 0:
+		return "match: " + i.Message
+	case i.Move < 0:
+		return fmt.Sprintf("game %d: %s", i.Game+1, i.Message)
+	}
+	return fmt.Sprintf("game %d, move %d: %s", i.Game+1, i.Move+1, i.Message)
+}
+
+// Validate checks that the match is internally consistent and returns the
+// issues found: scores that do not follow from previous results, points
+// that do not match the cube and result, unfinished games before the last
+// one, a match ending before a player reaches the match length, doubles in
+// the Crawford game, moves by the wrong player and dice outside 1-6.
+func (m *Match) Validate() []ValidationIssue {
+	var issues []ValidationIssue
+	add := func(game, move int, format string, args ...interface{}) {
+		issues = append(issues, ValidationIssue{Game: game, Move: move, Message: fmt.Sprintf(format, args...)})
+	}
+
+	matchLength := m.Metadata.MatchLength
+	var score [2]int
+
+	for gi := range m.Games {
+		game := &m.Games[gi]
+		last := gi == len(m.Games)-1
+
+		if gi > 0 && game.Score != score {
+			add(gi, -1, "score %d-%d does not follow from previous results (%d-%d)",
+				game.Score[0], game.Score[1], score[0], score[1])
+		}
+		if matchLength > 0 && (game.Score[0] >= matchLength || game.Score[1] >= matchLength) {
+			add(gi, -1, "game played after the match was won (score %d-%d)", game.Score[0], game.Score[1])
+		}
+		score = game.Score
+
+		if game.Winner == 0 || game.Winner == 1 {
+			score[game.Winner] += game.Points
+			if warning := classifyResult(copyGame(game), matchLength); warning != "" {
+				add(gi, -1, "%s", warning)
+			}
+		} else if !last {
+			add(gi, -1, "game has no winner but is not the last game")
+		}
+
+		issues = append(issues, validateMoves(gi, game)...)
+	}
+
+	if matchLength > 0 && len(m.Games) > 0 && score[0] < matchLength && score[1] < matchLength {
+		add(-1, -1, "match ends at %d-%d before either player reaches %d", score[0], score[1], matchLength)
+	}
+
+	return issues
+}
+
+// validateMoves checks the order of players and the dice of a game's moves
+func validateMoves(gi int, game *Game) []ValidationIssue {
+	var issues []ValidationIssue
+	add := func(move int, format string, args ...interface{}) {
+		issues = append(issues, ValidationIssue{Game: gi, Move: move, Message: fmt.Sprintf(format, args...)})
+	}
+
+	onRoll := -1   // Player expected to act next (-1 = unknown)
+	doubler := -1  // Player whose double awaits an answer
+	resigner := -1 // Player whose resignation awaits an answer
+
+	for i, mr := range game.Moves {
+		switch mr.Type {
+		case MoveTypeNormal:
+			if onRoll >= 0 && mr.Player != onRoll {
+				add(i, "player %d moved but player %d was on roll", mr.Player+1, onRoll+1)
+			}
+			for _, die := range mr.Dice {
+				if die < 1 || die > 6 {
+					add(i, "dice %d%d outside 1-6", mr.Dice[0], mr.Dice[1])
+					break
+				}
+			}
+			onRoll = 1 - mr.Player
+
+		case MoveTypeDouble:
+			if game.CrawfordGame {
+				add(i, "double during the Crawford game")
+			}
+			// A beaver redoubles right after taking
+			if onRoll >= 0 && mr.Player != onRoll && mr.Comment != "Beaver" {
+				add(i, "player %d doubled but player %d was on roll", mr.Player+1, onRoll+1)
+			}
+			doubler = mr.Player
+
+		case MoveTypeTake, MoveTypeDrop:
+			if doubler < 0 {
+				add(i, "%s without a double", mr.Type)
+			} else if mr.Player == doubler {
+				add(i, "player %d answered their own double", mr.Player+1)
+			}
+			doubler = -1
+
+		case MoveTypeResign:
+			resigner = mr.Player
+
+		case MoveTypeAccept, MoveTypeReject:
+			if resigner < 0 {
+				add(i, "%s without a resignation", mr.Type)
+			} else if mr.Player == resigner {
+				add(i, "player %d answered their own resignation", mr.Player+1)
+			}
+			resigner = -1
+
+		case MoveTypeSetBoard, MoveTypeSetDice:
+			// Setup records may change the player on roll
+			onRoll = -1
+		}
+	}
+
+	return issues
+}
+
+// copyGame returns a copy of a game whose moves can be modified freely
+func copyGame(game *Game) *Game {
+	c := *game
+	c.Moves = append([]MoveRecord(nil), game.Moves...)
+	return &c
+}
@@ -0,0 +1,77 @@
+package gnubgparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	move := func(player, d1, d2 int) MoveRecord {
+		return MoveRecord{Type: MoveTypeNormal, Player: player, Dice: [2]int{d1, d2}}
+	}
+
+	match := &Match{
+		Metadata: MatchMetadata{MatchLength: 5},
+		Games: []Game{
+			{Score: [2]int{0, 0}, Winner: 0, Points: 1, ResultKind: ResultSingle, Moves: []MoveRecord{
+				move(0, 3, 1),
+				move(0, 4, 2), // Same player twice
+				move(1, 7, 1), // Bad die
+			}},
+			// Score should be 1-0
+			{Score: [2]int{2, 0}, Winner: -1},
+			{Score: [2]int{2, 0}, Winner: 1, Points: 2, Moves: []MoveRecord{
+				{Type: MoveTypeDouble, Player: 0, CubeValue: 2},
+				{Type: MoveTypeDrop, Player: 0}, // Own double
+			}},
+			{Score: [2]int{4, 2}, CrawfordGame: true, Winner: 1, Points: 2, Moves: []MoveRecord{
+				{Type: MoveTypeDouble, Player: 1, CubeValue: 2},
+				{Type: MoveTypeTake, Player: 0},
+				{Type: MoveTypeResign, Player: 0, ResignLevel: 1},
+				{Type: MoveTypeAccept, Player: 1},
+			}},
+		},
+	}
+
+	var got []string
+	for _, issue := range match.Validate() {
+		got = append(got, issue.String())
+	}
+
+	want := []string{
+		"game 1, move 2: player 1 moved but player 2 was on roll",
+		"game 1, move 3: dice 71 outside 1-6",
+		"game 2: score 2-0 does not follow from previous results (1-0)",
+		"game 2: game has no winner but is not the last game",
+		"game 3: dropped double at cube 1 but 2 points won",
+		"game 3, move 2: player 1 answered their own double",
+		"game 4: score 4-2 does not follow from previous results (2-2)",
+		"game 4, move 1: double during the Crawford game",
+		"match: match ends at 4-4 before either player reaches 5",
+	}
+
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Validate() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidateFiles(t *testing.T) {
+	for _, file := range []string{
+		"test/charlot1-charlot2_7p_2025-11-08-2305.sgf",
+		"test/charlot1-charlot2_7p_2025-11-08-2305.mat",
+	} {
+		var match *Match
+		var err error
+		if strings.HasSuffix(file, ".mat") {
+			match, err = ParseMATFile(file)
+		} else {
+			match, err = ParseSGFFile(file)
+		}
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", file, err)
+		}
+		if issues := match.Validate(); len(issues) != 0 {
+			t.Errorf("%s: unexpected issues %v", file, issues)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package gnubgparser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// ViewOptions controls View
+type ViewOptions struct {
+	// Player drawn at the bottom of the board
+	Bottom int
+	// Analysed moves listed for each checker play (default 5); the played
+	// move is always listed
+	Alternatives int
+}
+
+// viewStep is one decision shown by View
+type viewStep struct {
+	game, move int
+	pos        Position
+	flagged    bool
+}
+
+// viewHelp is the key summary at the bottom of View's screen
+const viewHelp = "n/→ next  p/← previous  N/↓ P/↑ game  e/E next/previous error  g/G first/last  q quit"
+
+// View is an interactive replay of a match for ANSI terminals. It reads
+// single-key commands from in, which the caller should put in raw mode
+// (keys followed by Enter work too), and after each one redraws out with
+// the board, the action taken, the top analysed moves, the cube analysis,
+// luck and comments. Keys step through decisions, games and flagged
+// errors; View returns at "q" or the end of the input.
+func View(in io.Reader, out io.Writer, match *Match, opts ViewOptions) error {
+	alternatives := opts.Alternatives
+	if alternatives <= 0 {
+		alternatives = 5
+	}
+	names := playerNames(match)
+	steps := viewSteps(match)
+	if len(steps) == 0 {
+		return fmt.Errorf("match has no decisions to view")
+	}
+
+	r := bufio.NewReader(in)
+	w := bufio.NewWriter(out)
+	cur, status := 0, ""
+	for {
+		writeViewScreen(w, match, steps, cur, names, opts.Bottom, alternatives, status)
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		status = ""
+
+		key, err := readViewKey(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// find moves from cur in direction dir to the first step matching
+		// ok, reporting notFound when there is none
+		find := func(dir int, ok func(viewStep) bool, notFound string) {
+			for i := cur + dir; i >= 0 && i < len(steps); i += dir {
+				if ok(steps[i]) {
+					cur = i
+					return
+				}
+			}
+			status = notFound
+		}
+		game := steps[cur].game
+		switch key {
+		case "n", " ", "l", "right":
+			find(1, func(viewStep) bool { return true }, "Last decision of the match")
+		case "p", "h", "left":
+			find(-1, func(viewStep) bool { return true }, "First decision of the match")
+		case "N", "down":
+			find(1, func(s viewStep) bool { return s.game > game }, "Last game of the match")
+		case "P", "up":
+			if start := viewGameStart(steps, cur); start > 0 {
+				cur = viewGameStart(steps, start-1)
+			} else {
+				status = "First game of the match"
+			}
+		case "e":
+			find(1, func(s viewStep) bool { return s.flagged }, "No more errors")
+		case "E":
+			find(-1, func(s viewStep) bool { return s.flagged }, "No earlier errors")
+		case "g":
+			cur = 0
+		case "G":
+			cur = len(steps) - 1
+		case "q", "\x03", "\x04":
+			fmt.Fprint(w, "\n")
+			return w.Flush()
+		}
+	}
+}
+
+// viewSteps lists the checker plays and cube actions of a match with the
+// positions before them
+func viewSteps(match *Match) []viewStep {
+	var steps []viewStep
+	for g := range match.Games {
+		game := &match.Games[g]
+		positions := GamePositions(game, match.Metadata.MatchLength)
+		for i := range game.Moves {
+			mr := &game.Moves[i]
+			if _, _, ok := textDecision(mr, &positions[i], ""); !ok || (mr.Player != 0 && mr.Player != 1) {
+				continue
+			}
+			steps = append(steps, viewStep{game: g, move: i, pos: positions[i], flagged: worstRating(mr) != ""})
+		}
+	}
+	return steps
+}
+
+// viewGameStart returns the first step of the game of step i
+func viewGameStart(steps []viewStep, i int) int {
+	for i > 0 && steps[i-1].game == steps[i].game {
+		i--
+	}
+	return i
+}
+
+// readViewKey reads one key, naming the arrow keys and Escape and skipping
+// line ends
+func readViewKey(r *bufio.Reader) (string, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		switch b {
+		case '\r', '\n':
+			continue
+		case 0x1b:
+			// An arrow key arrives in one read as "ESC [ A"; a lone Escape
+			// has nothing buffered after it
+			if r.Buffered() == 0 {
+				return "escape", nil
+			}
+			if next, _ := r.ReadByte(); next != '[' {
+				r.UnreadByte()
+				return "escape", nil
+			}
+			if r.Buffered() == 0 {
+				return "escape", nil
+			}
+			arrow, _ := r.ReadByte()
+			if key, ok := map[byte]string{'A': "up", 'B': "down", 'C': "right", 'D': "left"}[arrow]; ok {
+				return key, nil
+			}
+			return "escape", nil
+		}
+		return string(b), nil
+	}
+}
+
+// writeViewScreen clears the terminal and draws one step
+func writeViewScreen(w *bufio.Writer, match *Match, steps []viewStep, cur int, names [2]string, bottom, alternatives int, status string) {
+	step := steps[cur]
+	game := &match.Games[step.game]
+	mr := &game.Moves[step.move]
+	pos := step.pos
+	matchLength := match.Metadata.MatchLength
+
+	fmt.Fprint(w, "\x1b[H\x1b[2J")
+	kind := "money session"
+	if matchLength > 0 {
+		kind = fmt.Sprintf("%d point match", matchLength)
+	}
+	fmt.Fprintf(w, "%s vs %s, %s  (decision %d of %d)\n", names[0], names[1], kind, cur+1, len(steps))
+	header, action, _ := textDecision(mr, &pos, names[mr.Player])
+	fmt.Fprintf(w, "Game %d of %d, move %d: %s\n\n", step.game+1, len(match.Games), step.move+1, header)
+	fmt.Fprint(w, pos.RenderASCII(ASCIIOptions{Bottom: bottom, Names: names, Checkers: checkersPerSide(game.Variation)}))
+	state := matchIDState{Turn: mr.Player, Doubled: mr.Type == MoveTypeTake || mr.Type == MoveTypeDrop}
+	fmt.Fprintf(w, "Position ID: %s  Match ID: %s\n\n", pos.PositionID(), pos.matchID(state))
+
+	fmt.Fprintf(w, "* %s\n", action)
+	writeTextAlert(w, mr)
+	if mr.Luck != nil {
+		fmt.Fprintf(w, "Luck: %+.3f (%s)\n", mr.Luck.Value, textRating(mr.Luck.Rating))
+	}
+	fmt.Fprintln(w)
+
+	if mr.Type == MoveTypeNormal && mr.Analysis != nil && len(mr.Analysis.Moves) > 0 {
+		writeTextMoves(w, mr, &pos, alternatives)
+	}
+	if mr.CubeAnalysis != nil {
+		writeTextCube(w, mr, game, matchLength, pos.CubeValue, match.MET)
+	}
+	if mr.Comment != "" {
+		fmt.Fprintf(w, "%s\n\n", mr.Comment)
+	}
+	if cur == len(steps)-1 || steps[cur+1].game != step.game {
+		fmt.Fprintf(w, "* %s\n\n", gameResultText(game, names))
+	}
+
+	if status != "" {
+		fmt.Fprintf(w, "[%s]\n", status)
+	}
+	fmt.Fprintln(w, viewHelp)
+}
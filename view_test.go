@@ -0,0 +1,55 @@
+package gnubgparser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestView(t *testing.T) {
+	match := &Match{
+		Metadata: MatchMetadata{Player1: "Alice", Player2: "Bob", MatchLength: 5},
+		Games: []Game{
+			{Winner: 0, Points: 1, Moves: []MoveRecord{
+				{Type: MoveTypeNormal, Player: 0, Dice: [2]int{3, 1}, SubMoves: []SubMove{{From: 7, To: 4}, {From: 5, To: 4}}},
+				{Type: MoveTypeNormal, Player: 1, Dice: [2]int{6, 5}, SubMoves: []SubMove{{From: 23, To: 17}, {From: 17, To: 12}},
+					Skill: &SkillRating{Rating: "VeryBad", Error: 0.2}, Comment: "Should run"},
+			}},
+			{Winner: 1, Points: 1, Score: [2]int{1, 0}, Moves: []MoveRecord{
+				{Type: MoveTypeNormal, Player: 1, Dice: [2]int{4, 2}, SubMoves: []SubMove{{From: 7, To: 3}, {From: 5, To: 3}}},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		keys string
+		want []string // Expected on the last screen drawn
+	}{
+		{"first decision", "q", []string{"(decision 1 of 3)", "Game 1 of 2, move 1: Alice to play 31", "* Alice moves 8/5 6/5"}},
+		{"next error", "e", []string{"(decision 2 of 3)", "Alert: very bad move (-0.200)", "Should run", "* Alice wins 1 point"}},
+		{"arrow keys and enter", "\x1b[C\n\x1b[Cn\x1b[D", []string{"(decision 2 of 3)"}},
+		{"lone escape", "n\x1b", []string{"(decision 2 of 3)"}},
+		{"escape then key", "\x1bn\x1b[Z", []string{"(decision 2 of 3)"}},
+		{"ctrl-c quits", "n\x03n", []string{"(decision 2 of 3)"}},
+		{"next game", "N", []string{"Game 2 of 2, move 1: Bob to play 42"}},
+		{"previous game", "GP", []string{"(decision 1 of 3)"}},
+		{"past the end", "Gn", []string{"(decision 3 of 3)", "[Last decision of the match]"}},
+		{"no more errors", "ee", []string{"(decision 2 of 3)", "[No more errors]"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := View(strings.NewReader(tt.keys), &out, match, ViewOptions{}); err != nil {
+				t.Fatalf("View failed: %v", err)
+			}
+			screens := strings.Split(out.String(), "\x1b[H\x1b[2J")
+			last := screens[len(screens)-1]
+			for _, want := range tt.want {
+				if !strings.Contains(last, want) {
+					t.Errorf("Screen does not contain %q:\n%s", want, last)
+				}
+			}
+		})
+	}
+}